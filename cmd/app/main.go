@@ -9,9 +9,13 @@ import (
 
 	"github.com/highway-to-Golang/user-service/config"
 	"github.com/highway-to-Golang/user-service/internal/app"
+	"github.com/highway-to-Golang/user-service/internal/correlation"
 )
 
 func main() {
+	logLevel := new(slog.LevelVar)
+	slog.SetDefault(slog.New(correlation.NewLogHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))))
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
@@ -21,7 +25,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	err = app.Run(ctx, cfg)
+	if err := logLevel.UnmarshalText([]byte(cfg.App.LogLevel)); err != nil {
+		slog.Error("Error loading config:", "error", err.Error())
+		os.Exit(1)
+	}
+
+	err = app.Run(ctx, cfg, logLevel)
 	if err != nil {
 		slog.Error("Error running app", "error", err.Error())
 		os.Exit(1)
@@ -1,34 +1,209 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// MinTokenSecretLength is the minimum length of AUTH_TOKEN_SECRET, the key
+// size of HS256.
+const MinTokenSecretLength = 32
+
 type (
 	Config struct {
-		PG    PG
-		HTTP  HTTP
-		NATS  NATS
-		Redis Redis
+		App               App
+		PG                PG
+		HTTP              HTTP
+		GRPC              GRPC
+		NATS              NATS
+		Redis             Redis
+		Auth              Auth
+		Lockout           Lockout
+		OIDC              OIDC
+		SCIM              SCIM
+		Invitations       Invitations
+		EmailVerification EmailVerification
+		Username          Username
+		Avatar            Avatar
+		Impersonation     Impersonation
+		Activity          Activity
+		OpenAPI           OpenAPI
+		GraphQL           GraphQL
+		RateLimit         RateLimit
+		Tracing           Tracing
+		Health            Health
+		Admin             Admin
+	}
+	App struct {
+		// Env is the deployment environment, e.g. "production", "staging" or
+		// "development". Development aids are never enabled in production.
+		Env string `env:"APP_ENV" env-default:"production"`
+		// ShutdownTimeout is the deadline of every component when shutting
+		// down, e.g. for the servers to drain in-flight requests.
+		ShutdownTimeout time.Duration `env:"APP_SHUTDOWN_TIMEOUT" env-default:"10s"`
+		// LogLevel is the initial log level, e.g. "debug" or "warn". It can be
+		// changed at runtime on the admin server.
+		LogLevel string `env:"LOG_LEVEL" env-default:"info"`
 	}
 	PG struct {
 		Host     string `env:"PG_HOST" env-default:"localhost"`
 		Port     string `env:"PG_PORT" env-default:"5432"`
 		User     string `env:"PG_USER" env-default:"postgres"`
-		Password string `env:"PG_PASSWORD" env-default:"postgres"`
+		Password string `env:"PG_PASSWORD" env-default:"postgres" redact:"true"`
 		Database string `env:"PG_DATABASE" env-default:"user_service"`
 	}
 	HTTP struct {
 		Host string `env:"HTTP_HOST" env-default:"localhost"`
 		Port string `env:"HTTP_PORT" env-default:"8080"`
 	}
+	GRPC struct {
+		Host string `env:"GRPC_HOST" env-default:"localhost"`
+		Port string `env:"GRPC_PORT" env-default:"9090"`
+		// ServiceToken authenticates internal callers with full access. Without
+		// it every RPC needs an access token.
+		ServiceToken string `env:"GRPC_SERVICE_TOKEN" redact:"true"`
+	}
 	NATS struct {
 		Enabled       bool   `env:"NATS_ENABLED" env-default:"false"`
-		URL           string `env:"NATS_URL" env-default:"nats://localhost:4222"`
+		URL           string `env:"NATS_URL" env-default:"nats://localhost:4222" redact:"url"`
 		SubjectPrefix string `env:"NATS_SUBJECT_PREFIX" env-default:"user.events"`
 	}
 	Redis struct {
-		URL string `env:"REDIS_URL" env-default:"redis://localhost:6379"`
+		URL string `env:"REDIS_URL" env-default:"redis://localhost:6379" redact:"url"`
+	}
+	Auth struct {
+		// TokenSecret signs access and MFA tokens (HS256). It is required and
+		// must be at least MinTokenSecretLength bytes.
+		TokenSecret      string        `env:"AUTH_TOKEN_SECRET" redact:"true"`
+		Issuer           string        `env:"AUTH_ISSUER" env-default:"user-service"`
+		AccessTokenTTL   time.Duration `env:"AUTH_ACCESS_TOKEN_TTL" env-default:"15m"`
+		MFATokenTTL      time.Duration `env:"AUTH_MFA_TOKEN_TTL" env-default:"5m"`
+		MFARequiredRoles []string      `env:"AUTH_MFA_REQUIRED_ROLES" env-default:"admin"`
+	}
+	Lockout struct {
+		Window              time.Duration `env:"LOCKOUT_WINDOW" env-default:"15m"`
+		AccountThreshold    int64         `env:"LOCKOUT_ACCOUNT_THRESHOLD" env-default:"10"`
+		IPThreshold         int64         `env:"LOCKOUT_IP_THRESHOLD" env-default:"50"`
+		Duration            time.Duration `env:"LOCKOUT_DURATION" env-default:"30m"`
+		BackoffFreeAttempts int64         `env:"LOCKOUT_BACKOFF_FREE_ATTEMPTS" env-default:"3"`
+		BackoffBase         time.Duration `env:"LOCKOUT_BACKOFF_BASE" env-default:"1s"`
+		BackoffMax          time.Duration `env:"LOCKOUT_BACKOFF_MAX" env-default:"5m"`
+	}
+	OIDC struct {
+		IssuerURL string `env:"OIDC_ISSUER_URL" env-default:"http://localhost:8080"`
+		// SigningKeyPath is the path of a PEM encoded RSA private key. It is
+		// required in production; elsewhere an ephemeral key is generated at
+		// startup.
+		SigningKeyPath string        `env:"OIDC_SIGNING_KEY_PATH"`
+		CodeTTL        time.Duration `env:"OIDC_CODE_TTL" env-default:"1m"`
+		IDTokenTTL     time.Duration `env:"OIDC_ID_TOKEN_TTL" env-default:"1h"`
+	}
+	SCIM struct {
+		Token string `env:"SCIM_TOKEN" redact:"true"`
+	}
+	EmailVerification struct {
+		TTL time.Duration `env:"EMAIL_VERIFICATION_TTL" env-default:"24h"`
+		URL string        `env:"EMAIL_VERIFICATION_URL" env-default:"http://localhost:8080/email/verify"`
+	}
+	Username struct {
+		CaseFold          bool          `env:"USERNAME_CASE_FOLD" env-default:"true"`
+		Pattern           string        `env:"USERNAME_PATTERN" env-default:"^[\\p{L}\\p{N}_.]+$"`
+		MinLength         int           `env:"USERNAME_MIN_LENGTH" env-default:"3"`
+		MaxLength         int           `env:"USERNAME_MAX_LENGTH" env-default:"30"`
+		DetectConfusables bool          `env:"USERNAME_DETECT_CONFUSABLES" env-default:"true"`
+		Reserved          []string      `env:"USERNAME_RESERVED" env-default:"admin,administrator,root,system,support,help,api,me,settings,login,logout,null,undefined"`
+		ReleaseCooldown   time.Duration `env:"USERNAME_RELEASE_COOLDOWN" env-default:"720h"`
+	}
+	Avatar struct {
+		StorageDir   string `env:"AVATAR_STORAGE_DIR" env-default:"./data/blobs"`
+		BaseURL      string `env:"AVATAR_BASE_URL" env-default:"http://localhost:8080"`
+		MaxBytes     int64  `env:"AVATAR_MAX_BYTES" env-default:"5242880"`
+		MaxDimension int    `env:"AVATAR_MAX_DIMENSION" env-default:"4096"`
+		Sizes        []int  `env:"AVATAR_SIZES" env-default:"64,128,256"`
+	}
+	Impersonation struct {
+		TTL time.Duration `env:"IMPERSONATION_TTL" env-default:"15m"`
+		// Writes is "block" to reject write requests made while impersonating,
+		// or "flag" to allow them and record a security event.
+		Writes string `env:"IMPERSONATION_WRITES" env-default:"block"`
+	}
+	Activity struct {
+		// SeenThrottle is the minimum time between two last_seen_at updates of
+		// the same user.
+		SeenThrottle  time.Duration `env:"ACTIVITY_SEEN_THROTTLE" env-default:"5m"`
+		FlushInterval time.Duration `env:"ACTIVITY_FLUSH_INTERVAL" env-default:"1m"`
+		FlushBatch    int           `env:"ACTIVITY_FLUSH_BATCH" env-default:"500"`
+
+		InactiveWarnAfter    time.Duration `env:"INACTIVE_WARN_AFTER" env-default:"1440h"`
+		InactiveSuspendAfter time.Duration `env:"INACTIVE_SUSPEND_AFTER" env-default:"2160h"`
+		InactiveInterval     time.Duration `env:"INACTIVE_CHECK_INTERVAL" env-default:"24h"`
+		InactiveDryRun       bool          `env:"INACTIVE_DRY_RUN" env-default:"true"`
+	}
+	OpenAPI struct {
+		// Validate checks every request and response against the embedded
+		// OpenAPI document. Responses are buffered, so it is ignored when Env
+		// is "production".
+		Validate bool `env:"OPENAPI_VALIDATE" env-default:"false"`
+	}
+	GraphQL struct {
+		MaxDepth      int `env:"GRAPHQL_MAX_DEPTH" env-default:"8"`
+		MaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" env-default:"5000"`
+		// ListSize is the assumed length of lists without a "first" argument
+		// when estimating the complexity of a query.
+		ListSize          int           `env:"GRAPHQL_LIST_SIZE" env-default:"20"`
+		PersistedQueryTTL time.Duration `env:"GRAPHQL_PERSISTED_QUERY_TTL" env-default:"720h"`
+	}
+	RateLimit struct {
+		Enabled bool `env:"RATE_LIMIT_ENABLED" env-default:"true"`
+		// Window is the period of the limits below. Clients may burst up to a
+		// limit, after which requests are spread evenly over the window. A
+		// limit of 0 disables it.
+		Window time.Duration `env:"RATE_LIMIT_WINDOW" env-default:"1m"`
+		IP     int           `env:"RATE_LIMIT_IP" env-default:"600"`
+		// APIKey limits each credential sent in the X-API-Key or Authorization
+		// header.
+		APIKey int `env:"RATE_LIMIT_API_KEY" env-default:"1200"`
+		// Routes limits single routes for each client IP, keyed by the pattern of
+		// the route, e.g. "POST /api/users:20,POST /api/auth/login:30".
+		Routes map[string]int `env:"RATE_LIMIT_ROUTES" env-default:"POST /api/users:30,POST /api/auth/login:30,POST /api/auth/login/mfa:30"`
+	}
+	Tracing struct {
+		// Exporter is "otlp" to export spans to an OTLP collector over gRPC,
+		// "stdout" to print them, e.g. in tests, or "none".
+		Exporter     string `env:"TRACING_EXPORTER" env-default:"none"`
+		OTLPEndpoint string `env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4317"`
+		OTLPInsecure bool   `env:"TRACING_OTLP_INSECURE" env-default:"true"`
+		// SampleRatio is the share of traces started by this service that are
+		// sampled. Traces started by a caller follow its sampling decision.
+		SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+		ServiceName string  `env:"TRACING_SERVICE_NAME" env-default:"user-service"`
+	}
+	Health struct {
+		// CheckTimeout bounds every dependency check of the readiness probe.
+		CheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+		// Optional lists checks, e.g. "redis,nats", whose failure degrades the
+		// service without making it unready.
+		Optional []string `env:"HEALTH_OPTIONAL"`
+		// ShutdownDelay is how long the servers keep accepting requests after
+		// readiness started failing on shutdown, so that the orchestrator
+		// notices before connections are refused. It should exceed the readiness
+		// probe period.
+		ShutdownDelay time.Duration `env:"HEALTH_SHUTDOWN_DELAY" env-default:"0s"`
+	}
+	Admin struct {
+		// The admin server exposes pprof, build info, the effective
+		// configuration and the log level. It is only started if Token is set,
+		// and must not be reachable from outside the host or cluster.
+		Host  string `env:"ADMIN_HOST" env-default:"localhost"`
+		Port  string `env:"ADMIN_PORT" env-default:"9091"`
+		Token string `env:"ADMIN_TOKEN" redact:"true"`
+	}
+	Invitations struct {
+		TTL            time.Duration `env:"INVITATIONS_TTL" env-default:"72h"`
+		AcceptURL      string        `env:"INVITATIONS_ACCEPT_URL" env-default:"http://localhost:8080/invitations/accept"`
+		ExpiryInterval time.Duration `env:"INVITATIONS_EXPIRY_INTERVAL" env-default:"1h"`
 	}
 )
 
@@ -40,5 +215,15 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if len(config.Auth.TokenSecret) < MinTokenSecretLength {
+		return nil, fmt.Errorf("AUTH_TOKEN_SECRET must be set to at least %d bytes", MinTokenSecretLength)
+	}
+
+	// An ephemeral OIDC key changes on every restart and differs between
+	// replicas, which invalidates the ID tokens relying parties hold.
+	if config.App.Env == "production" && config.OIDC.SigningKeyPath == "" {
+		return nil, fmt.Errorf("OIDC_SIGNING_KEY_PATH must be set when APP_ENV is production")
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns the configuration as nested maps keyed by field name, for
+// display. Fields tagged redact:"true" are replaced unless empty, and fields
+// tagged redact:"url" keep everything but the password of the URL.
+func (c Config) Redacted() map[string]any {
+	return redactStruct(reflect.ValueOf(c))
+}
+
+func redactStruct(v reflect.Value) map[string]any {
+	out := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+
+		switch {
+		case value.Kind() == reflect.Struct:
+			out[field.Name] = redactStruct(value)
+		case field.Tag.Get("redact") == "true" && !value.IsZero():
+			out[field.Name] = redacted
+		case field.Tag.Get("redact") == "url":
+			out[field.Name] = redactURL(value.String())
+		case value.Type() == reflect.TypeFor[time.Duration]():
+			out[field.Name] = time.Duration(value.Int()).String()
+		default:
+			out[field.Name] = value.Interface()
+		}
+	}
+	return out
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
+}
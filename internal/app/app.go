@@ -2,66 +2,211 @@ package app
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/blob"
 	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/grpc"
 	"github.com/highway-to-Golang/user-service/internal/http"
+	"github.com/highway-to-Golang/user-service/internal/lifecycle"
 	"github.com/highway-to-Golang/user-service/internal/nats"
+	"github.com/highway-to-Golang/user-service/internal/notify"
 	"github.com/highway-to-Golang/user-service/internal/redis"
 	"github.com/highway-to-Golang/user-service/internal/repository"
+	"github.com/highway-to-Golang/user-service/internal/scheduler"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 	"github.com/highway-to-Golang/user-service/internal/usecase"
+	"github.com/highway-to-Golang/user-service/internal/username"
 )
 
-func Run(ctx context.Context, cfg *config.Config) error {
+// Run starts the service and blocks until ctx is done or a server fails. The
+// components are then stopped in reverse order of their start: readiness
+// fails, the servers stop accepting traffic and drain in-flight requests, the
+// scheduled jobs stop, activity is flushed, NATS is drained, and Redis and the
+// database pool are closed.
+func Run(ctx context.Context, cfg *config.Config, logLevel *slog.LevelVar) (err error) {
+	lc := lifecycle.New(cfg.App.ShutdownTimeout)
+	defer func() {
+		err = errors.Join(err, lc.Shutdown())
+	}()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	lc.OnStop("tracing", shutdownTracing)
+
 	db, err := database.NewDB(ctx, *cfg)
 	if err != nil {
 		return err
 	}
-	defer db.Pool.Close()
+	lc.OnStop("postgres", func(context.Context) error {
+		db.Pool.Close()
+		return nil
+	})
 
 	userRepo := repository.NewUserRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
 
-	var eventSink *nats.EventSink
-	if cfg.NATS.Enabled {
-		es, err := nats.New(cfg.NATS.URL, cfg.NATS.SubjectPrefix)
+	oidcKeys, err := auth.LoadKeySet(cfg.OIDC.SigningKeyPath)
+	if err != nil {
+		return err
+	}
+	if cfg.OIDC.SigningKeyPath == "" {
+		slog.WarnContext(ctx, "OIDC_SIGNING_KEY_PATH is not set, using an ephemeral signing key", "env", cfg.App.Env)
+	}
+
+	usernamePolicy, err := username.NewPolicy(username.Rules{
+		CaseFold:          cfg.Username.CaseFold,
+		Pattern:           cfg.Username.Pattern,
+		MinLength:         cfg.Username.MinLength,
+		MaxLength:         cfg.Username.MaxLength,
+		DetectConfusables: cfg.Username.DetectConfusables,
+		Reserved:          cfg.Username.Reserved,
+	})
+	if err != nil {
+		return err
+	}
+
+	blobStore, err := blob.NewFSStore(cfg.Avatar.StorageDir)
+	if err != nil {
+		return err
+	}
+
+	healthChecks := []usecase.HealthCheck{{Name: "postgres", Check: db.Pool.Ping}}
+
+	var idempotencyStorage *redis.IdempotencyStorage
+	var loginAttemptStorage *redis.LoginAttemptStorage
+	var activityStorage *redis.ActivityStorage
+	var persistedQueryStorage *redis.PersistedQueryStorage
+	var rateLimitStorage *redis.RateLimitStorage
+	if cfg.Redis.URL != "" {
+		redisClient, err := redis.NewClient(cfg.Redis.URL)
 		if err != nil {
 			return err
 		}
-		defer es.Close()
-		eventSink = es
+		lc.OnStop("redis", func(context.Context) error {
+			return redisClient.Close()
+		})
+		idempotencyStorage = redis.NewIdempotencyStorage(redisClient)
+		loginAttemptStorage = redis.NewLoginAttemptStorage(redisClient)
+		activityStorage = redis.NewActivityStorage(redisClient)
+		persistedQueryStorage = redis.NewPersistedQueryStorage(redisClient)
+		rateLimitStorage = redis.NewRateLimitStorage(redisClient)
+		healthChecks = append(healthChecks, usecase.HealthCheck{Name: "redis", Check: func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}})
 	}
 
-	var idempotencyStorage *redis.IdempotencyStorage
-	if cfg.Redis.URL != "" {
-		is, err := redis.NewIdempotencyStorage(cfg.Redis.URL)
+	var eventSink *nats.EventSink
+	if cfg.NATS.Enabled {
+		es, err := nats.New(cfg.NATS.URL, cfg.NATS.SubjectPrefix)
 		if err != nil {
 			return err
 		}
-		defer is.Close()
-		idempotencyStorage = is
+		lc.OnStop("nats", es.Drain)
+		eventSink = es
+		healthChecks = append(healthChecks, usecase.HealthCheck{Name: "nats", Check: es.Check})
 	}
 
-	userUC := usecase.New(userRepo, eventSink, idempotencyStorage, cfg)
+	userUC := usecase.New(userRepo, eventSink, idempotencyStorage, cfg,
+		usecase.WithMFARepository(mfaRepo),
+		usecase.WithLoginAttemptStorage(loginAttemptStorage),
+		usecase.WithActivityStorage(activityStorage),
+		usecase.WithPersistedQueryStorage(persistedQueryStorage),
+		usecase.WithRateLimitStorage(rateLimitStorage),
+		usecase.WithOIDC(oidcRepo, oidcKeys),
+		usecase.WithGroupRepository(groupRepo),
+		usecase.WithInvitationRepository(invitationRepo),
+		usecase.WithEmailVerificationRepository(emailVerificationRepo),
+		usecase.WithUsernamePolicy(usernamePolicy),
+		usecase.WithBlobStore(blobStore),
+		usecase.WithImpersonationRepository(impersonationRepo),
+		usecase.WithNotifier(notify.NewLogNotifier()),
+		usecase.WithHealthChecks(healthChecks...),
+	)
 	userHandler := http.NewUserHandler(userUC)
 	server := http.NewServer(*cfg, userHandler)
+	grpcServer := grpc.NewServer(*cfg, userUC)
 
-	go func() {
-		if err := server.Start(); err != nil {
-			slog.Error("HTTP server error", "error", err)
-		}
-	}()
+	lc.OnStop("activity", userUC.FlushActivity)
 
-	<-ctx.Done()
-	slog.Info("shutdown signal received")
+	jobs := []struct {
+		name     string
+		interval time.Duration
+		run      func(ctx context.Context) error
+	}{
+		{"expire_invitations", cfg.Invitations.ExpiryInterval, userUC.ExpireInvitations},
+		{"flush_activity", cfg.Activity.FlushInterval, userUC.FlushActivity},
+		{"suspend_inactive_users", cfg.Activity.InactiveInterval, userUC.SuspendInactiveUsers},
+	}
+	// Jobs outlive ctx until their stop hook, so that none runs while the
+	// stores it uses are closed.
+	jobsCtx, stopJobs := context.WithCancel(context.WithoutCancel(ctx))
+	var running sync.WaitGroup
+	for _, job := range jobs {
+		running.Go(func() {
+			scheduler.Every(jobsCtx, job.name, job.interval, job.run)
+		})
+	}
+	lc.OnStop("scheduler", func(context.Context) error {
+		stopJobs()
+		running.Wait()
+		return nil
+	})
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if cfg.Admin.Token != "" {
+		adminServer := http.NewAdminServer(*cfg, logLevel)
+		lc.OnStop("admin server", adminServer.Shutdown)
+		if err := adminServer.Listen(); err != nil {
+			return err
+		}
+		lc.Go("admin server", adminServer.Serve)
+	} else {
+		slog.InfoContext(ctx, "ADMIN_TOKEN is not set, the admin server is disabled")
+	}
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
+	// Both servers stop accepting traffic at once and drain in-flight
+	// requests within the same deadline.
+	lc.OnStop("servers", func(ctx context.Context) error {
+		shutdownErrs := make(chan error, 1)
+		go func() {
+			shutdownErrs <- grpcServer.Shutdown(ctx)
+		}()
+		return errors.Join(server.Shutdown(ctx), <-shutdownErrs)
+	})
+
+	if err := server.Listen(); err != nil {
+		return err
+	}
+	lc.Go("http server", server.Serve)
+	if err := grpcServer.Listen(); err != nil {
 		return err
 	}
+	lc.Go("grpc server", grpcServer.Serve)
+
+	// Readiness fails first, and the servers keep accepting requests for
+	// the shutdown delay, so that traffic is routed elsewhere before
+	// connections are refused.
+	lc.OnStop("readiness", func(ctx context.Context) error {
+		userUC.BeginShutdown()
+		select {
+		case <-time.After(cfg.Health.ShutdownDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, lifecycle.WithTimeout(cfg.Health.ShutdownDelay+time.Second))
 
-	return nil
+	return lc.Wait(ctx)
 }
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p domain.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (domain.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(domain.Principal)
+	return p, ok
+}
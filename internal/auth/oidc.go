@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TokenUseAccess = "access"
+	TokenUseID     = "id"
+)
+
+// OIDCClaims covers both ID tokens and access tokens issued by the OIDC
+// provider. TokenUse tells them apart so an ID token cannot be replayed
+// against the userinfo endpoint.
+type OIDCClaims struct {
+	TokenUse string           `json:"token_use"`
+	Scope    string           `json:"scope,omitempty"`
+	Nonce    string           `json:"nonce,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	Name     string           `json:"name,omitempty"`
+	Email    string           `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
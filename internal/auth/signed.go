@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// SignedToken returns a single-use token of the form id.nonce.signature. The
+// signature lets a token be rejected before any lookup; callers still store
+// HashToken of it to enforce single use.
+func SignedToken(secret, id string) (string, error) {
+	nonce, err := RandomToken(24)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(id)) + "." + nonce
+	return payload + "." + sign(secret, payload), nil
+}
+
+// VerifySignedToken checks the signature of a token created by SignedToken and
+// returns the embedded id.
+func VerifySignedToken(secret, token string) (string, bool) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return "", false
+	}
+
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(sign(secret, payload)), []byte(signature)) {
+		return "", false
+	}
+
+	encodedID, _, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", false
+	}
+
+	id, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil {
+		return "", false
+	}
+
+	return string(id), true
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
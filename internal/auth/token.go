@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Claims struct {
+	Role    string `json:"role"`
+	Purpose string `json:"purpose"`
+	Act     *Actor `json:"act,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Actor identifies who is acting on behalf of the subject, as in the "act"
+// claim of RFC 8693.
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+type TokenIssuer struct {
+	secret []byte
+	issuer string
+}
+
+func NewTokenIssuer(secret, issuer string) *TokenIssuer {
+	return &TokenIssuer{
+		secret: []byte(secret),
+		issuer: issuer,
+	}
+}
+
+func (t *TokenIssuer) Issue(userID, role, purpose string, ttl time.Duration) (string, time.Time, error) {
+	return t.issue(Claims{Role: role, Purpose: purpose}, userID, uuid.NewString(), ttl)
+}
+
+// IssueImpersonation issues an access token for userID that records actorID
+// as the real actor. The session ID becomes the token ID.
+func (t *TokenIssuer) IssueImpersonation(userID, role, actorID, sessionID string, ttl time.Duration) (string, time.Time, error) {
+	claims := Claims{
+		Role:    role,
+		Purpose: domain.TokenPurposeAccess,
+		Act:     &Actor{Subject: actorID},
+	}
+	return t.issue(claims, userID, sessionID, ttl)
+}
+
+func (t *TokenIssuer) issue(claims Claims, userID, tokenID string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        tokenID,
+		Issuer:    t.issuer,
+		Subject:   userID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+func (t *TokenIssuer) Parse(token string) (domain.Principal, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return t.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(t.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	principal := domain.Principal{
+		UserID:  claims.Subject,
+		Role:    claims.Role,
+		Purpose: claims.Purpose,
+	}
+	if claims.Act != nil {
+		principal.ActorID = claims.Act.Subject
+		principal.SessionID = claims.ID
+	}
+
+	return principal, nil
+}
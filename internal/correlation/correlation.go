@@ -0,0 +1,140 @@
+// Package correlation ties the work done for a request together: log lines,
+// error responses and published events all carry the same request and trace
+// IDs, which are taken from the X-Request-ID and W3C traceparent headers of
+// the request or generated.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceParentHeader = "traceparent"
+
+	// maxRequestIDLength bounds client supplied request IDs, which end up in
+	// every log line of the request.
+	maxRequestIDLength = 128
+)
+
+// IDs identifies a request. SpanID is generated for the request itself, so
+// that work it causes elsewhere, e.g. event consumers, is a child of it.
+type IDs struct {
+	RequestID string
+	TraceID   string
+	SpanID    string
+	Sampled   bool
+}
+
+// Headers is implemented by http.Header and nats.Header.
+type Headers interface {
+	Get(key string) string
+}
+
+// FromHeaders takes the IDs of a request from its headers. Missing or
+// malformed IDs are generated, and the trace ID doubles as request ID if the
+// client sent none.
+func FromHeaders(h Headers) IDs {
+	ids := IDs{SpanID: randomHex(8)}
+
+	if traceID, flags, ok := parseTraceParent(h.Get(TraceParentHeader)); ok {
+		ids.TraceID = traceID
+		ids.Sampled = flags&1 == 1
+	} else {
+		ids.TraceID = randomHex(16)
+	}
+
+	ids.RequestID = ids.TraceID
+	if id := h.Get(RequestIDHeader); validRequestID(id) {
+		ids.RequestID = id
+	}
+
+	return ids
+}
+
+// New returns fresh IDs for work that does not belong to a request.
+func New() IDs {
+	traceID := randomHex(16)
+	return IDs{RequestID: traceID, TraceID: traceID, SpanID: randomHex(8)}
+}
+
+// WithSpan returns ids with the trace of span, if it is valid, so that logs
+// and errors name the trace that is exported.
+func (ids IDs) WithSpan(span trace.SpanContext) IDs {
+	if !span.IsValid() {
+		return ids
+	}
+	if ids.RequestID == ids.TraceID {
+		ids.RequestID = span.TraceID().String()
+	}
+	ids.TraceID = span.TraceID().String()
+	ids.SpanID = span.SpanID().String()
+	ids.Sampled = span.IsSampled()
+	return ids
+}
+
+type idsKey struct{}
+
+func WithIDs(ctx context.Context, ids IDs) context.Context {
+	return context.WithValue(ctx, idsKey{}, ids)
+}
+
+func IDsFromContext(ctx context.Context) (IDs, bool) {
+	ids, ok := ctx.Value(idsKey{}).(IDs)
+	return ids, ok
+}
+
+// parseTraceParent parses a version 00 traceparent header, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func parseTraceParent(header string) (string, byte, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return "", 0, false
+	}
+
+	traceID, parentID, flags := parts[1], parts[2], parts[3]
+	if !isHex(traceID, 32) || !isHex(parentID, 16) || !isHex(flags, 2) ||
+		strings.Trim(traceID, "0") == "" || strings.Trim(parentID, "0") == "" {
+		return "", 0, false
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return traceID, b[0], true
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// validRequestID accepts printable ASCII IDs, so that client supplied IDs
+// cannot inject anything into logs or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
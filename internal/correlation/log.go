@@ -0,0 +1,40 @@
+package correlation
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogHandler adds the request and trace IDs stored in the context to every
+// record. Records must be logged with the *Context variants of slog, e.g.
+// slog.InfoContext, to carry them.
+type LogHandler struct {
+	next slog.Handler
+}
+
+func NewLogHandler(next slog.Handler) *LogHandler {
+	return &LogHandler{next: next}
+}
+
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ids, ok := IDsFromContext(ctx); ok {
+		record = record.Clone()
+		record.AddAttrs(
+			slog.String("request_id", ids.RequestID),
+			slog.String("trace_id", ids.TraceID),
+		)
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{next: h.next.WithGroup(name)}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 
 	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/metrics"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -26,6 +27,7 @@ func NewDB(ctx context.Context, cfg config.Config) (*DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
+	poolConfig.ConnConfig.Tracer = queryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -36,7 +38,9 @@ func NewDB(ctx context.Context, cfg config.Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	slog.Info("connected to database", "host", cfg.PG.Host, "port", cfg.PG.Port)
+	metrics.RegisterPool(pool)
+
+	slog.InfoContext(ctx, "connected to database", "host", cfg.PG.Host, "port", cfg.PG.Port)
 
 	return &DB{Pool: pool}, nil
 }
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/highway-to-Golang/user-service/internal/metrics"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// repositoryPackage is the package whose methods name the operations that
+// queries are attributed to.
+const repositoryPackage = "/internal/repository."
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	operation string
+	at        time.Time
+	span      trace.Span
+}
+
+// queryTracer records the duration of every query, and a client span for it,
+// by the repository method that ran it, e.g. "UserRepository.GetByID". The
+// method is found on the call stack, so repositories do not have to name their
+// queries. The SQL is not recorded, as goqu inlines the query arguments.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	op := operation()
+	ctx, span := tracing.Tracer().Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(op),
+		),
+	)
+	return context.WithValue(ctx, queryStartKey{}, queryStart{operation: op, at: time.Now(), span: span})
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	tracing.End(start.span, data.Err)
+	metrics.QueryDuration.WithLabelValues(start.operation, metrics.Outcome(data.Err)).Observe(time.Since(start.at).Seconds())
+}
+
+// operation returns the repository method on the call stack, or "other" for
+// queries run elsewhere, e.g. by migrations.
+func operation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if _, name, ok := strings.Cut(frame.Function, repositoryPackage); ok {
+			// "(*UserRepository).GetByID.func1" becomes "UserRepository.GetByID".
+			name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+			if receiver, method, ok := strings.Cut(name, "."); ok {
+				method, _, _ = strings.Cut(method, ".")
+				return receiver + "." + method
+			}
+			return name
+		}
+		if !more {
+			return "other"
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Activity is the latest known activity of a user. LastLoginAt is nil when
+// the user only made requests since the last flush.
+type Activity struct {
+	UserID      string
+	LastSeenAt  time.Time
+	LastLoginAt *time.Time
+}
+
+// UserFilter narrows down user listings. Zero values do not filter.
+type UserFilter struct {
+	// InactiveSince selects users without any activity since the given time.
+	// Users who never logged in count as active from their creation.
+	InactiveSince *time.Time
+	// IDs selects only the users with one of the given IDs.
+	IDs []string
+	// Limit and Offset page through the users, newest first.
+	Limit  int
+	Offset int
+}
+
+type InactiveUser struct {
+	ID           string     `json:"id"`
+	Email        string     `json:"email"`
+	LastActiveAt time.Time  `json:"last_active_at"`
+	WarnedAt     *time.Time `json:"warned_at,omitempty"`
+}
+
+// InactivityReport lists the users warned and suspended by one inactivity
+// check. In a dry run nothing was changed.
+type InactivityReport struct {
+	DryRun    bool           `json:"dry_run"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Warned    []InactiveUser `json:"warned"`
+	Suspended []InactiveUser `json:"suspended"`
+}
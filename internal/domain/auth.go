@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+var (
+	ErrInvalidCredentials = errors.ErrInvalidCredentials
+	ErrUnauthorized       = errors.ErrUnauthorized
+	ErrForbidden          = errors.ErrForbidden
+	ErrAccountInactive    = errors.ErrAccountInactive
+)
+
+const (
+	TokenPurposeAccess    = "access"
+	TokenPurposeMFA       = "mfa"
+	TokenPurposeMFAEnroll = "mfa_enroll"
+)
+
+type Principal struct {
+	UserID  string
+	Role    string
+	Purpose string
+
+	// ActorID is the admin acting as UserID when the token was issued for an
+	// impersonation session identified by SessionID.
+	ActorID   string
+	SessionID string
+}
+
+func (p Principal) Impersonated() bool {
+	return p.ActorID != ""
+}
+
+// CanManage reports whether p may change the account of userID: users may
+// change their own account and admins any account.
+func (p Principal) CanManage(userID string) bool {
+	return p.UserID == userID || p.Role == RoleAdmin
+}
+
+// CanChangeRole reports whether p may change the role of a user. Only admins
+// may, so that users cannot promote themselves.
+func (p Principal) CanChangeRole() bool {
+	return p.Role == RoleAdmin
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+
+	// IP is the source address of the request, set by the transport layer.
+	IP string `json:"-"`
+}
+
+type LoginMFARequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+
+	IP string `json:"-"`
+}
+
+// LoginResult is returned by the first login step. Either AccessToken is set,
+// or one of the MFA flags is set together with a short-lived MFAToken that
+// must be exchanged in the second step.
+type LoginResult struct {
+	AccessToken           string    `json:"access_token,omitempty"`
+	TokenType             string    `json:"token_type,omitempty"`
+	ExpiresAt             time.Time `json:"expires_at,omitzero"`
+	MFARequired           bool      `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool      `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string    `json:"mfa_token,omitempty"`
+}
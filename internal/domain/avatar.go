@@ -0,0 +1,9 @@
+package domain
+
+import "github.com/highway-to-Golang/user-service/internal/errors"
+
+var (
+	ErrAvatarsNotEnabled      = errors.ErrNotEnabled
+	ErrAvatarTooLarge         = errors.ErrTooLarge
+	ErrUnsupportedAvatarImage = errors.ErrUnsupportedMediaType
+)
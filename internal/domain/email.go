@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/validation"
+)
+
+// EmailVerification is a single-use token proving ownership of Email.
+type EmailVerification struct {
+	TokenHash string
+	UserID    string
+	Email     string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// UserEmail is one of the addresses of a user. Exactly one is primary and
+// mirrors User.Email.
+type UserEmail struct {
+	Email      string     `json:"email"`
+	Primary    bool       `json:"primary"`
+	VerifiedAt *time.Time `json:"verified_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type AddUserEmailRequest struct {
+	Email string `json:"email"`
+}
+
+func (r *AddUserEmailRequest) Normalize() {
+	r.Email = validation.Normalize(r.Email)
+}
+
+func (r AddUserEmailRequest) Validate() error {
+	var v validation.Validator
+	v.Field("email", r.Email, validation.Required, validation.MaxLength(MaxEmailLength), validation.Email)
+	return v.Err("invalid email")
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+type GroupMember struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type Group struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Members   []GroupMember `json:"members"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type CreateGroupRequest struct {
+	Name      string   `json:"name"`
+	MemberIDs []string `json:"member_ids"`
+}
+
+// UpdateGroupRequest replaces the fields that are set. A non-nil MemberIDs
+// replaces the whole membership.
+type UpdateGroupRequest struct {
+	Name      *string   `json:"name,omitempty"`
+	MemberIDs *[]string `json:"member_ids,omitempty"`
+}
+
+func (g Group) MemberIDs() []string {
+	ids := make([]string, 0, len(g.Members))
+	for _, m := range g.Members {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+func NewGroup(name string) (Group, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Group{}, errors.ErrFailedToBuild
+	}
+	return Group{
+		ID:   id.String(),
+		Name: name,
+	}, nil
+}
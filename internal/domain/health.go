@@ -0,0 +1,30 @@
+package domain
+
+const (
+	HealthStatusOK           = "ok"
+	HealthStatusDegraded     = "degraded"
+	HealthStatusUnavailable  = "unavailable"
+	HealthStatusShuttingDown = "shutting_down"
+
+	HealthCheckPass = "pass"
+	HealthCheckFail = "fail"
+)
+
+// HealthReport is the readiness of the service with the result of every
+// dependency check. The service is degraded if only non-critical checks fail.
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+// Ready reports whether the service should receive traffic.
+func (r HealthReport) Ready() bool {
+	return r.Status == HealthStatusOK || r.Status == HealthStatusDegraded
+}
+
+type HealthCheckResult struct {
+	Status     string `json:"status"`
+	Critical   bool   `json:"critical"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+const (
+	ImpersonationWritesBlock = "block"
+	ImpersonationWritesFlag  = "flag"
+)
+
+// ImpersonationSession records an admin acting as another user.
+type ImpersonationSession struct {
+	ID        string    `json:"id"`
+	ActorID   string    `json:"actor_id"`
+	UserID    string    `json:"user_id"`
+	Reason    string    `json:"reason"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason"`
+
+	// IP is the source address of the request, set by the transport layer.
+	IP string `json:"-"`
+}
+
+type ImpersonationResult struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	SessionID   string    `json:"session_id"`
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/highway-to-Golang/user-service/internal/errors"
+	"github.com/highway-to-Golang/user-service/internal/validation"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+var (
+	ErrInvitationNotPending = errors.ErrInvitationNotPending
+	ErrInvitationExpired    = errors.ErrInvitationExpired
+)
+
+type Invitation struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	GroupIDs   []string   `json:"group_ids"`
+	Status     string     `json:"status"`
+	InvitedBy  string     `json:"invited_by"`
+	UserID     string     `json:"user_id,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	TokenHash string `json:"-"`
+}
+
+type CreateInvitationRequest struct {
+	Email    string   `json:"email"`
+	Role     string   `json:"role"`
+	GroupIDs []string `json:"group_ids,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+func (r *AcceptInvitationRequest) Normalize() {
+	r.Name = validation.Normalize(r.Name)
+}
+
+// Validate applies the same name and password rules as CreateUserRequest. A
+// password is required since invited users have no other way to log in.
+func (r AcceptInvitationRequest) Validate() error {
+	var v validation.Validator
+	v.Field("token", r.Token, validation.Required)
+	v.Field("name", r.Name, validation.Required, validation.MaxLength(MaxNameLength), validation.Printable)
+	v.Field("password", r.Password, validation.Required, validation.MaxBytes(MaxPasswordLength))
+	return v.Err("invalid invitation acceptance")
+}
+
+func NewInvitation(email, role string, groupIDs []string, invitedBy string, expiresAt time.Time) (Invitation, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Invitation{}, errors.ErrFailedToBuild
+	}
+	if groupIDs == nil {
+		groupIDs = []string{}
+	}
+	return Invitation{
+		ID:        id.String(),
+		Email:     email,
+		Role:      role,
+		GroupIDs:  groupIDs,
+		Status:    InvitationPending,
+		InvitedBy: invitedBy,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+var (
+	ErrTooManyAttempts = errors.ErrTooManyAttempts
+	ErrAccountLocked   = errors.ErrAccountLocked
+)
+
+// ThrottledError is returned when a login is rejected because of backoff or
+// an account lock. It matches ErrTooManyAttempts or ErrAccountLocked.
+type ThrottledError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", e.Err, e.RetryAfter.Round(time.Second))
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+var (
+	ErrInvalidMFACode    = errors.ErrInvalidMFACode
+	ErrMFAAlreadyEnabled = errors.ErrMFAAlreadyEnabled
+	ErrMFANotEnabled     = errors.ErrMFANotEnabled
+)
+
+type MFA struct {
+	UserID       string
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+	ConfirmedAt  *time.Time
+	CreatedAt    time.Time
+}
+
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type MFAConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+type MFAConfirmation struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFADisableRequest struct {
+	Code string `json:"code"`
+}
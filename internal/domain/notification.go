@@ -0,0 +1,9 @@
+package domain
+
+// Notification is a message sent to a user out of band, e.g. by email.
+type Notification struct {
+	To      string
+	Subject string
+	Body    string
+	Link    string
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// RateLimit allows Requests requests per Period for Key. Requests may burst up
+// to the limit and are then spread evenly over the period (GCRA).
+type RateLimit struct {
+	Key      string
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitState is the state of a single limit after a request was checked.
+// RetryAfter is zero if the limit allowed the request.
+type RateLimitState struct {
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitDecision is the outcome of checking a request against several
+// limits. It describes the most restrictive one.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      RateLimit
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// NewRateLimitDecision combines the states of limits. The request is allowed
+// only if every limit allowed it.
+func NewRateLimitDecision(limits []RateLimit, states []RateLimitState) RateLimitDecision {
+	decision := RateLimitDecision{Allowed: true, Remaining: -1}
+
+	for i, state := range states {
+		if state.RetryAfter > 0 {
+			decision.Allowed = false
+			decision.RetryAfter = max(decision.RetryAfter, state.RetryAfter)
+		}
+		if decision.Remaining < 0 || state.Remaining < decision.Remaining ||
+			(state.Remaining == decision.Remaining && state.Reset > decision.Reset) {
+			decision.Limit = limits[i]
+			decision.Remaining = state.Remaining
+			decision.Reset = state.Reset
+		}
+	}
+	decision.Remaining = max(decision.Remaining, 0)
+
+	return decision
+}
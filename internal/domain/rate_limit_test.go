@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitDecision(t *testing.T) {
+	perSecond := RateLimit{Key: "ip", Requests: 10, Period: time.Second}
+	perMinute := RateLimit{Key: "key", Requests: 100, Period: time.Minute}
+
+	tests := []struct {
+		name   string
+		states []RateLimitState
+		want   RateLimitDecision
+	}{
+		{
+			name: "fewest remaining wins",
+			states: []RateLimitState{
+				{Remaining: 9, Reset: 100 * time.Millisecond},
+				{Remaining: 5, Reset: 30 * time.Second},
+			},
+			want: RateLimitDecision{Allowed: true, Limit: perMinute, Remaining: 5, Reset: 30 * time.Second},
+		},
+		{
+			name: "later reset breaks a tie",
+			states: []RateLimitState{
+				{Remaining: 5, Reset: 500 * time.Millisecond},
+				{Remaining: 5, Reset: 30 * time.Second},
+			},
+			want: RateLimitDecision{Allowed: true, Limit: perMinute, Remaining: 5, Reset: 30 * time.Second},
+		},
+		{
+			name: "rejected by one limit",
+			states: []RateLimitState{
+				{Reset: time.Second, RetryAfter: 100 * time.Millisecond},
+				{Remaining: 50, Reset: 30 * time.Second},
+			},
+			want: RateLimitDecision{Limit: perSecond, Reset: time.Second, RetryAfter: 100 * time.Millisecond},
+		},
+		{
+			name: "longest retry after of rejecting limits",
+			states: []RateLimitState{
+				{Reset: time.Second, RetryAfter: 100 * time.Millisecond},
+				{Reset: time.Minute, RetryAfter: 20 * time.Second},
+			},
+			want: RateLimitDecision{Limit: perMinute, Reset: time.Minute, RetryAfter: 20 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewRateLimitDecision([]RateLimit{perSecond, perMinute}, tt.states)
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
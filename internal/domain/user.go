@@ -1,35 +1,186 @@
 package domain
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/highway-to-Golang/user-service/internal/errors"
+	"github.com/highway-to-Golang/user-service/internal/validation"
 )
 
 var (
-	ErrNotFound = errors.ErrNotFound
+	ErrNotFound     = errors.ErrNotFound
+	ErrInvalidInput = errors.ErrInvalidInput
+	ErrConflict     = errors.ErrConflict
+
+	ErrInvalidTransition = errors.ErrInvalidTransition
+	ErrPatchTestFailed   = errors.ErrPatchTestFailed
+)
+
+// Roles are the roles a user can have.
+var Roles = []string{RoleUser, RoleAdmin}
+
+// Field limits of users. Emails are limited by RFC 5321, passwords by bcrypt.
+const (
+	MaxNameLength     = 255
+	MaxEmailLength    = 254
+	MaxPasswordLength = 72
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User statuses. New users whose email is not verified yet are pending and
+// become active once they verify it.
+const (
+	StatusPending   = "pending"
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusDisabled  = "disabled"
+	StatusDeleted   = "deleted"
 )
 
 type User struct {
 	ID        string    `json:"id"`
 	Name      string    `json:"name"`
+	Username  string    `json:"username,omitempty"`
 	Email     string    `json:"email"`
 	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	Status          string     `json:"status"`
+	StatusReason    string     `json:"status_reason,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+
+	// EmailVerifiedAt is nil until the owner of Email confirmed it. A
+	// requested address change is kept in PendingEmail until confirmed.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	PendingEmail    string     `json:"pending_email,omitempty"`
+
+	// AvatarURLs maps the pixel size of each avatar variant to its URL.
+	AvatarURLs    map[string]string `json:"avatar_urls,omitempty"`
+	AvatarVersion string            `json:"-"`
+
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+
+	// LockedUntil is set while the account is temporarily locked after too
+	// many failed logins.
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+
+	PasswordHash string `json:"-"`
+	// UsernameKey is the normalized form of Username that decides uniqueness.
+	UsernameKey string `json:"-"`
+}
+
+type ChangeStatusRequest struct {
+	Reason string `json:"reason"`
 }
 
 type CreateUserRequest struct {
-	Email string `json:"email"`
-	Name  string `json:"name"`
-	Role  string `json:"role"`
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role"`
+	Password string `json:"password,omitempty"`
+
+	// EmailVerified is set by trusted callers, e.g. invitations or SCIM, that
+	// have already established ownership of Email.
+	EmailVerified bool `json:"-"`
+	// Disabled is set by provisioning callers, e.g. SCIM, to create the
+	// account in the disabled status.
+	Disabled bool `json:"-"`
 }
 
 type UpdateUserRequest struct {
 	Email *string `json:"email,omitempty"`
 	Name  *string `json:"name,omitempty"`
 	Role  string  `json:"role,omitempty"`
+
+	// Username sets a new username; an empty string removes it.
+	Username *string `json:"username,omitempty"`
+
+	// EmailVerified applies an email change immediately instead of staging it
+	// until the new address is confirmed. It is only set by trusted callers.
+	EmailVerified bool `json:"-"`
+}
+
+// Normalize trims and NFC-normalizes the textual fields.
+func (r *CreateUserRequest) Normalize() {
+	r.Email = validation.Normalize(r.Email)
+	r.Name = validation.Normalize(r.Name)
+	r.Role = strings.TrimSpace(r.Role)
+}
+
+// Validate reports all invalid fields at once. An empty role is valid and
+// means the default role.
+func (r CreateUserRequest) Validate() error {
+	var v validation.Validator
+	v.Field("email", r.Email, validation.Required, validation.MaxLength(MaxEmailLength), validation.Email)
+	v.Field("name", r.Name, validation.Required, validation.MaxLength(MaxNameLength), validation.Printable)
+	if r.Role != "" {
+		v.Field("role", r.Role, validation.OneOf(Roles...))
+	}
+	if r.Password != "" {
+		v.Field("password", r.Password, validation.MaxBytes(MaxPasswordLength))
+	}
+	return v.Err("invalid user")
+}
+
+func (r *UpdateUserRequest) Normalize() {
+	if r.Email != nil {
+		email := validation.Normalize(*r.Email)
+		r.Email = &email
+	}
+	if r.Name != nil {
+		name := validation.Normalize(*r.Name)
+		r.Name = &name
+	}
+	r.Role = strings.TrimSpace(r.Role)
+}
+
+// Validate checks the fields that are set. Usernames are checked against the
+// username policy when they are claimed.
+func (r UpdateUserRequest) Validate() error {
+	var v validation.Validator
+	if r.Email != nil {
+		v.Field("email", *r.Email, validation.Required, validation.MaxLength(MaxEmailLength), validation.Email)
+	}
+	if r.Name != nil {
+		v.Field("name", *r.Name, validation.Required, validation.MaxLength(MaxNameLength), validation.Printable)
+	}
+	if r.Role != "" {
+		v.Field("role", r.Role, validation.OneOf(Roles...))
+	}
+	return v.Err("invalid user")
+}
+
+// Patch formats accepted for partial user updates.
+const (
+	PatchFormatMerge = "merge-patch"
+	PatchFormatJSON  = "json-patch"
+)
+
+// PatchableUser is the document that PATCH requests operate on. Role and
+// username may be removed or set to null; a removed role falls back to the
+// default role.
+type PatchableUser struct {
+	Email    string  `json:"email"`
+	Name     string  `json:"name"`
+	Role     *string `json:"role"`
+	Username *string `json:"username"`
+}
+
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
 }
 
 func NewUser(name, email, role string) (User, error) {
@@ -38,9 +189,10 @@ func NewUser(name, email, role string) (User, error) {
 		return User{}, errors.ErrFailedToBuild
 	}
 	return User{
-		ID:    id.String(),
-		Name:  name,
-		Email: email,
-		Role:  role,
+		ID:     id.String(),
+		Name:   name,
+		Email:  email,
+		Role:   role,
+		Status: StatusActive,
 	}, nil
 }
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+const (
+	UsernameAvailable = "available"
+	UsernameInvalid   = "invalid"
+	UsernameReserved  = "reserved"
+	UsernameTaken     = "taken"
+	UsernameCooldown  = "cooldown"
+)
+
+type UsernameAvailability struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message,omitempty"`
+}
+
+// UsernameHold keeps a released username from being claimed by anyone but
+// its previous owner until AvailableAt.
+type UsernameHold struct {
+	Key         string
+	UserID      string
+	AvailableAt time.Time
+}
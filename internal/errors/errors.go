@@ -7,4 +7,21 @@ var (
 	ErrFailedToBuild            = errors.New("failed to build")
 	ErrInvalidInput             = errors.New("invalid input")
 	ErrRequestAlreadyInProgress = errors.New("request already in progress")
+	ErrInvalidCredentials       = errors.New("invalid credentials")
+	ErrUnauthorized             = errors.New("unauthorized")
+	ErrForbidden                = errors.New("forbidden")
+	ErrInvalidMFACode           = errors.New("invalid mfa code")
+	ErrMFAAlreadyEnabled        = errors.New("mfa already enabled")
+	ErrMFANotEnabled            = errors.New("mfa not enabled")
+	ErrTooManyAttempts          = errors.New("too many attempts")
+	ErrAccountLocked            = errors.New("account locked")
+	ErrConflict                 = errors.New("already exists")
+	ErrInvalidTransition        = errors.New("invalid status transition")
+	ErrAccountInactive          = errors.New("account is not active")
+	ErrInvitationNotPending     = errors.New("invitation is not pending")
+	ErrInvitationExpired        = errors.New("invitation expired")
+	ErrPatchTestFailed          = errors.New("patch test failed")
+	ErrNotEnabled               = errors.New("not enabled")
+	ErrTooLarge                 = errors.New("too large")
+	ErrUnsupportedMediaType     = errors.New("unsupported media type")
 )
@@ -0,0 +1,166 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrUnavailable marks failures of a dependency such as the database, which
+// are usually temporary.
+var ErrUnavailable = errors.New("dependency unavailable")
+
+// Error is an error with a stable machine-readable code, the HTTP status it
+// maps to and optional field violations. It wraps one of the sentinel errors
+// above, so errors.Is keeps working on it.
+type Error struct {
+	Code       string
+	Status     int
+	Message    string
+	Violations []Violation
+	Err        error
+}
+
+// Violation describes why a single request field is invalid.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if len(e.Violations) == 0 {
+		return e.Message
+	}
+
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Field+": "+v.Message)
+	}
+	return e.Message + " (" + strings.Join(parts, "; ") + ")"
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Validation returns an invalid input error listing the offending fields.
+func Validation(message string, violations ...Violation) *Error {
+	return &Error{
+		Code:       "validation_failed",
+		Status:     http.StatusBadRequest,
+		Message:    message,
+		Violations: violations,
+		Err:        ErrInvalidInput,
+	}
+}
+
+// Unavailable wraps a failure of the named dependency.
+func Unavailable(dependency string, err error) *Error {
+	return &Error{
+		Code:    "dependency_unavailable",
+		Status:  http.StatusServiceUnavailable,
+		Message: dependency + " is unavailable",
+		Err:     fmt.Errorf("%w: %w", ErrUnavailable, err),
+	}
+}
+
+type sentinel struct {
+	err    error
+	code   string
+	status int
+}
+
+// sentinels maps the sentinel errors to their code and status. The first
+// match wins, so more specific errors come first.
+var sentinels = []sentinel{
+	{ErrUnavailable, "dependency_unavailable", http.StatusServiceUnavailable},
+	{ErrInvalidInput, "validation_failed", http.StatusBadRequest},
+	{ErrNotFound, "not_found", http.StatusNotFound},
+	{ErrConflict, "conflict", http.StatusConflict},
+	{ErrRequestAlreadyInProgress, "request_in_progress", http.StatusConflict},
+	{ErrInvalidCredentials, "invalid_credentials", http.StatusUnauthorized},
+	{ErrUnauthorized, "unauthorized", http.StatusUnauthorized},
+	{ErrForbidden, "forbidden", http.StatusForbidden},
+	{ErrInvalidMFACode, "invalid_mfa_code", http.StatusUnauthorized},
+	{ErrMFAAlreadyEnabled, "mfa_already_enabled", http.StatusConflict},
+	{ErrMFANotEnabled, "mfa_not_enabled", http.StatusConflict},
+	{ErrTooManyAttempts, "too_many_attempts", http.StatusTooManyRequests},
+	{ErrAccountLocked, "account_locked", http.StatusLocked},
+	{ErrInvalidTransition, "invalid_transition", http.StatusConflict},
+	{ErrAccountInactive, "account_inactive", http.StatusForbidden},
+	{ErrInvitationNotPending, "invitation_not_pending", http.StatusConflict},
+	{ErrInvitationExpired, "invitation_expired", http.StatusGone},
+	{ErrPatchTestFailed, "patch_test_failed", http.StatusConflict},
+	{ErrNotEnabled, "not_enabled", http.StatusNotFound},
+	{ErrTooLarge, "too_large", http.StatusRequestEntityTooLarge},
+	{ErrUnsupportedMediaType, "unsupported_media_type", http.StatusUnsupportedMediaType},
+}
+
+// From converts any error into an *Error. Typed errors are returned as is,
+// sentinel errors get their code and status, failures to reach a dependency
+// become ErrUnavailable and everything else is an internal error whose
+// message is not exposed.
+func From(err error) *Error {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed
+	}
+
+	if IsUnavailable(err) && !errors.Is(err, ErrUnavailable) {
+		err = fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			message := err.Error()
+			if s.status >= http.StatusInternalServerError {
+				message = s.err.Error()
+			}
+			return &Error{Code: s.code, Status: s.status, Message: message, Err: err}
+		}
+	}
+
+	return &Error{
+		Code:    "internal_error",
+		Status:  http.StatusInternalServerError,
+		Message: "internal error",
+		Err:     err,
+	}
+}
+
+// CodeForStatus returns the generic code of an HTTP status, e.g. "bad_request".
+func CodeForStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+// IsUnavailable reports whether err is caused by a dependency that could not
+// be reached or refused to serve the request: network errors, timeouts and
+// PostgreSQL connection, shutdown and resource errors.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var sqlErr interface{ SQLState() string }
+	if errors.As(err, &sqlErr) {
+		state := sqlErr.SQLState()
+		return strings.HasPrefix(state, "08") || strings.HasPrefix(state, "53") || strings.HasPrefix(state, "57P")
+	}
+
+	return false
+}
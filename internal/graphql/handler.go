@@ -0,0 +1,252 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// maxBodyBytes limits the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// Handler serves GraphQL over HTTP. Queries are accepted with GET and POST,
+// mutations only with POST.
+type Handler struct {
+	uc     *usecase.UseCase
+	cfg    config.GraphQL
+	schema graphql.Schema
+}
+
+// NewHandler builds the schema and returns the handler. The schema is static,
+// so failing to build it is a programming error and panics.
+func NewHandler(uc *usecase.UseCase, cfg config.GraphQL) *Handler {
+	schema, err := newSchema(uc, cfg.ListSize)
+	if err != nil {
+		panic(fmt.Sprintf("graphql: invalid schema: %v", err))
+	}
+
+	return &Handler{uc: uc, cfg: cfg, schema: schema}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *struct {
+			Version    int    `json:"version"`
+			SHA256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// response is the result of an executed operation. Requests that fail before
+// execution are answered with errors only, without data.
+type response struct {
+	Data   interface{}                `json:"data"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(w, r)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, requestError("bad_request", err.Error()))
+		return
+	}
+
+	// Automatic persisted queries: clients first send only the hash and, if
+	// it is unknown, retry with the full query, which is then registered.
+	// The messages and codes are the ones Apollo clients look for.
+	persist := false
+	if pq := req.Extensions.PersistedQuery; pq != nil {
+		if !h.uc.PersistedQueriesEnabled() {
+			writeErrors(w, http.StatusOK, requestError("PERSISTED_QUERY_NOT_SUPPORTED", "PersistedQueryNotSupported"))
+			return
+		}
+		if req.Query == "" {
+			query, err := h.uc.GetPersistedQuery(r.Context(), pq.SHA256Hash)
+			if errors.Is(err, domain.ErrNotFound) {
+				writeErrors(w, http.StatusOK, requestError("PERSISTED_QUERY_NOT_FOUND", "PersistedQueryNotFound"))
+				return
+			}
+			if err != nil {
+				writeErrors(w, apperrors.From(err).Status, formatError(r.Context(), gqlerrors.FormatError(err)))
+				return
+			}
+			req.Query = query
+		} else {
+			persist = true
+		}
+	}
+	if req.Query == "" {
+		writeErrors(w, http.StatusBadRequest, requestError("bad_request", "query is required"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, withCode(gqlerrors.FormatError(err), "graphql_parse_failed"))
+		return
+	}
+
+	if result := graphql.ValidateDocument(&h.schema, doc, nil); !result.IsValid {
+		for i := range result.Errors {
+			result.Errors[i] = withCode(result.Errors[i], "graphql_validation_failed")
+		}
+		writeErrors(w, http.StatusBadRequest, result.Errors...)
+		return
+	}
+
+	op, err := analyze(h.schema, doc, req.OperationName, req.Variables, h.cfg.ListSize)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, requestError("graphql_validation_failed", err.Error()))
+		return
+	}
+	if op.depth > h.cfg.MaxDepth {
+		writeErrors(w, http.StatusBadRequest, requestError("query_too_deep",
+			fmt.Sprintf("query depth %d exceeds the limit of %d", op.depth, h.cfg.MaxDepth)))
+		return
+	}
+	if op.complexity > h.cfg.MaxComplexity {
+		writeErrors(w, http.StatusBadRequest, requestError("query_too_complex",
+			fmt.Sprintf("query complexity %d exceeds the limit of %d", op.complexity, h.cfg.MaxComplexity)))
+		return
+	}
+	if op.kind == ast.OperationTypeMutation && r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeErrors(w, http.StatusMethodNotAllowed, requestError("method_not_allowed", "mutations require POST"))
+		return
+	}
+	// Queries are reads whatever the method, so only mutations are writes
+	// that need to be allowed while impersonating.
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.Impersonated() && op.kind == ast.OperationTypeMutation {
+		if err := h.uc.AuthorizeImpersonatedWrite(r.Context(), principal, r.Method, r.URL.Path); err != nil {
+			writeErrors(w, http.StatusForbidden, requestError("forbidden", "write operations are not allowed while impersonating"))
+			return
+		}
+	}
+
+	if persist {
+		if err := h.uc.PersistQuery(r.Context(), req.Extensions.PersistedQuery.SHA256Hash, req.Query); err != nil {
+			writeErrors(w, apperrors.From(err).Status, formatError(r.Context(), gqlerrors.FormatError(err)))
+			return
+		}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(r.Context(), newLoaders(h.uc)),
+	})
+	for i := range result.Errors {
+		result.Errors[i] = formatError(r.Context(), result.Errors[i])
+	}
+
+	writeJSON(w, http.StatusOK, response{Data: result.Data, Errors: result.Errors})
+}
+
+// decodeRequest reads the request from the query string of a GET or the JSON
+// body of a POST.
+func decodeRequest(w http.ResponseWriter, r *http.Request) (request, error) {
+	var req request
+
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return request{}, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+		if v := q.Get("extensions"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Extensions); err != nil {
+				return request{}, fmt.Errorf("invalid extensions: %w", err)
+			}
+		}
+		return req, nil
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(&req); err != nil {
+		return request{}, fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return req, nil
+}
+
+// formatError replaces the message of an error returned by a resolver with
+// the one of its application error and adds its code and violations, so that
+// clients can handle errors the same way as with the REST API.
+func formatError(ctx context.Context, formatted gqlerrors.FormattedError) gqlerrors.FormattedError {
+	err := originalError(formatted)
+	if err == nil {
+		return withCode(formatted, "graphql_validation_failed")
+	}
+
+	e := apperrors.From(err)
+	if e.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(ctx, "graphql resolver failed", "error", err, "code", e.Code, "path", formatted.Path)
+	}
+
+	formatted.Message = e.Message
+	formatted.Extensions = map[string]interface{}{"code": e.Code}
+	if len(e.Violations) > 0 {
+		formatted.Extensions["violations"] = e.Violations
+	}
+	return formatted
+}
+
+// originalError returns the error a resolver failed with, or nil if the error
+// was raised by graphql-go itself.
+func originalError(formatted gqlerrors.FormattedError) error {
+	err := formatted.OriginalError()
+	for {
+		var gqlErr *gqlerrors.Error
+		if !errors.As(err, &gqlErr) {
+			return err
+		}
+		if gqlErr.OriginalError == nil {
+			return nil
+		}
+		err = gqlErr.OriginalError
+	}
+}
+
+func requestError(code, message string) gqlerrors.FormattedError {
+	return withCode(gqlerrors.NewFormattedError(message), code)
+}
+
+func withCode(formatted gqlerrors.FormattedError, code string) gqlerrors.FormattedError {
+	formatted.Extensions = map[string]interface{}{"code": code}
+	return formatted
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs ...gqlerrors.FormattedError) {
+	writeJSON(w, status, map[string]interface{}{"errors": errs})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error("failed to write graphql response", "error", err)
+	}
+}
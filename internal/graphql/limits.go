@@ -0,0 +1,189 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// maxCost caps intermediate complexity values so that nested lists with huge
+// "first" arguments cannot overflow.
+const maxCost = math.MaxInt32
+
+// operation is the result of analysing an operation before it is executed.
+type operation struct {
+	kind       string
+	depth      int
+	complexity int
+}
+
+// analyzer computes the depth and complexity of an operation. Every field
+// costs 1, and the fields below a list cost as many times as the list is
+// long: the value of its "first" argument or the configured list size.
+// Introspection fields are free, since the schema is small and static.
+type analyzer struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	listSize  int
+	visiting  map[string]bool
+}
+
+func analyze(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}, listSize int) (operation, error) {
+	a := &analyzer{
+		schema:    schema,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		listSize:  listSize,
+		visiting:  make(map[string]bool),
+	}
+
+	var selected *ast.OperationDefinition
+	count := 0
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			count++
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				selected = d
+			}
+		}
+	}
+	switch {
+	case selected == nil:
+		return operation{}, fmt.Errorf("unknown operation %q", operationName)
+	case operationName == "" && count > 1:
+		return operation{}, errors.New("operationName is required when the document contains several operations")
+	}
+
+	var root graphql.Type = schema.QueryType()
+	if selected.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	depth, complexity := a.selectionSet(selected.SelectionSet, root, 0)
+	return operation{kind: selected.Operation, depth: depth, complexity: complexity}, nil
+}
+
+func (a *analyzer) selectionSet(set *ast.SelectionSet, parent graphql.Type, depth int) (int, int) {
+	if set == nil {
+		return depth, 0
+	}
+
+	maxDepth, cost := depth, 0
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			fieldType, isList := a.fieldType(parent, s.Name.Value)
+			d, c = a.selectionSet(s.SelectionSet, fieldType, depth+1)
+			if isList {
+				c = saturate(int64(c) * int64(a.listLength(s)))
+			}
+			c = saturate(int64(c) + 1)
+
+		case *ast.FragmentSpread:
+			fragment, ok := a.fragments[s.Name.Value]
+			if !ok || a.visiting[s.Name.Value] {
+				// Unknown and cyclic fragments are rejected by validation.
+				continue
+			}
+			a.visiting[s.Name.Value] = true
+			d, c = a.selectionSet(fragment.SelectionSet, a.typeCondition(fragment.TypeCondition, parent), depth)
+			a.visiting[s.Name.Value] = false
+
+		case *ast.InlineFragment:
+			d, c = a.selectionSet(s.SelectionSet, a.typeCondition(s.TypeCondition, parent), depth)
+		}
+
+		maxDepth = max(maxDepth, d)
+		cost = saturate(int64(cost) + int64(c))
+	}
+
+	return maxDepth, cost
+}
+
+// fieldsType is implemented by the object and interface types of the schema.
+type fieldsType interface {
+	Fields() graphql.FieldDefinitionMap
+}
+
+// fieldType returns the named type of a field and whether it is a list.
+func (a *analyzer) fieldType(parent graphql.Type, name string) (graphql.Type, bool) {
+	fields, ok := parent.(fieldsType)
+	if !ok {
+		return nil, false
+	}
+	def, ok := fields.Fields()[name]
+	if !ok {
+		return nil, false
+	}
+
+	var t graphql.Type = def.Type
+	isList := false
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			isList = true
+			t = wrapped.OfType
+		default:
+			return t, isList
+		}
+	}
+}
+
+func (a *analyzer) typeCondition(condition *ast.Named, parent graphql.Type) graphql.Type {
+	if condition == nil {
+		return parent
+	}
+	return a.schema.Type(condition.Name.Value)
+}
+
+func (a *analyzer) listLength(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return max(n, 0)
+			}
+		case *ast.Variable:
+			if n, ok := intValue(a.variables[v.Name.Value]); ok {
+				return max(n, 0)
+			}
+		}
+	}
+	return a.listSize
+}
+
+func intValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
+
+func saturate(n int64) int {
+	return int(min(n, maxCost))
+}
@@ -0,0 +1,93 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// loader batches the keys requested while a query executes and fetches them
+// with a single call once the first value is needed. Resolvers return the
+// thunk of load, and graphql-go only calls thunks after resolving all fields
+// of the same depth, so every element of a list enqueues its key before the
+// batch is fetched.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	results map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		queued:  make(map[K]bool),
+		results: make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// load enqueues key and returns a thunk that yields its value. Values are
+// cached for the rest of the request; missing keys yield the zero value.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+
+			results, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.results[k] = results[k]
+			}
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
+
+// loaders holds the loaders of one request, so that cached values never leak
+// between requests.
+type loaders struct {
+	users    *loader[string, domain.User]
+	groups   *loader[string, []domain.Group]
+	members  *loader[string, []domain.GroupMember]
+	sessions *loader[string, []domain.ImpersonationSession]
+}
+
+func newLoaders(uc *usecase.UseCase) *loaders {
+	return &loaders{
+		users:    newLoader(uc.GetUsersByIDs),
+		groups:   newLoader(uc.GetUserGroups),
+		members:  newLoader(uc.GetGroupMembers),
+		sessions: newLoader(uc.ListImpersonationSessionsByUserIDs),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,481 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// resolver builds the schema. Related data is always fetched through the
+// request's loaders, so that a list of n users costs one repository call per
+// relation instead of n.
+type resolver struct {
+	uc       *usecase.UseCase
+	listSize int
+}
+
+func newSchema(uc *usecase.UseCase, listSize int) (graphql.Schema, error) {
+	r := &resolver{uc: uc, listSize: listSize}
+
+	roleEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Role",
+		Values: graphql.EnumValueConfigMap{
+			"USER":  &graphql.EnumValueConfig{Value: domain.RoleUser},
+			"ADMIN": &graphql.EnumValueConfig{Value: domain.RoleAdmin},
+		},
+	})
+	statusEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "UserStatus",
+		Values: graphql.EnumValueConfigMap{
+			"PENDING":   &graphql.EnumValueConfig{Value: domain.StatusPending},
+			"ACTIVE":    &graphql.EnumValueConfig{Value: domain.StatusActive},
+			"SUSPENDED": &graphql.EnumValueConfig{Value: domain.StatusSuspended},
+			"DISABLED":  &graphql.EnumValueConfig{Value: domain.StatusDisabled},
+			"DELETED":   &graphql.EnumValueConfig{Value: domain.StatusDeleted},
+		},
+	})
+	actionEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "UserStatusAction",
+		Values: graphql.EnumValueConfigMap{
+			"ACTIVATE": &graphql.EnumValueConfig{Value: "activate"},
+			"SUSPEND":  &graphql.EnumValueConfig{Value: "suspend"},
+			"DISABLE":  &graphql.EnumValueConfig{Value: "disable"},
+			"DELETE":   &graphql.EnumValueConfig{Value: "delete"},
+			"RESTORE":  &graphql.EnumValueConfig{Value: "restore"},
+		},
+	})
+
+	avatarType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Avatar",
+		Fields: graphql.Fields{
+			"size": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"url":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	var userType, groupType *graphql.Object
+
+	memberType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "GroupMember",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"userId": memberField(graphql.NewNonNull(graphql.ID), func(m domain.GroupMember) interface{} { return m.UserID }),
+				"name":   memberField(graphql.NewNonNull(graphql.String), func(m domain.GroupMember) interface{} { return m.Name }),
+				"user": &graphql.Field{
+					Type: userType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return r.loadUser(p.Context, p.Source.(domain.GroupMember).UserID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	sessionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ImpersonationSession",
+		Description: "An audited session in which an admin acted as the user.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        sessionField(graphql.NewNonNull(graphql.ID), func(s domain.ImpersonationSession) interface{} { return s.ID }),
+				"reason":    sessionField(graphql.NewNonNull(graphql.String), func(s domain.ImpersonationSession) interface{} { return s.Reason }),
+				"ip":        sessionField(graphql.String, func(s domain.ImpersonationSession) interface{} { return optionalString(s.IP) }),
+				"createdAt": sessionField(graphql.NewNonNull(graphql.DateTime), func(s domain.ImpersonationSession) interface{} { return s.CreatedAt }),
+				"expiresAt": sessionField(graphql.NewNonNull(graphql.DateTime), func(s domain.ImpersonationSession) interface{} { return s.ExpiresAt }),
+				"actor": &graphql.Field{
+					Type: userType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return r.loadUser(p.Context, p.Source.(domain.ImpersonationSession).ActorID), nil
+					},
+				},
+				"user": &graphql.Field{
+					Type: userType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return r.loadUser(p.Context, p.Source.(domain.ImpersonationSession).UserID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	groupType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Group",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        groupField(graphql.NewNonNull(graphql.ID), func(g domain.Group) interface{} { return g.ID }),
+				"name":      groupField(graphql.NewNonNull(graphql.String), func(g domain.Group) interface{} { return g.Name }),
+				"createdAt": groupField(graphql.NewNonNull(graphql.DateTime), func(g domain.Group) interface{} { return g.CreatedAt }),
+				"updatedAt": groupField(graphql.NewNonNull(graphql.DateTime), func(g domain.Group) interface{} { return g.UpdatedAt }),
+				"members": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(memberType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						group := p.Source.(domain.Group)
+						if group.Members != nil {
+							return group.Members, nil
+						}
+						return list(loadersFrom(p.Context).members.load(p.Context, group.ID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":              userField(graphql.NewNonNull(graphql.ID), func(u domain.User) interface{} { return u.ID }),
+				"name":            userField(graphql.NewNonNull(graphql.String), func(u domain.User) interface{} { return u.Name }),
+				"username":        userField(graphql.String, func(u domain.User) interface{} { return optionalString(u.Username) }),
+				"email":           userField(graphql.NewNonNull(graphql.String), func(u domain.User) interface{} { return u.Email }),
+				"role":            userField(graphql.NewNonNull(roleEnum), func(u domain.User) interface{} { return u.Role }),
+				"status":          userField(graphql.NewNonNull(statusEnum), func(u domain.User) interface{} { return u.Status }),
+				"statusReason":    userField(graphql.String, func(u domain.User) interface{} { return optionalString(u.StatusReason) }),
+				"statusChangedAt": userField(graphql.DateTime, func(u domain.User) interface{} { return optionalTime(u.StatusChangedAt) }),
+				"emailVerifiedAt": userField(graphql.DateTime, func(u domain.User) interface{} { return optionalTime(u.EmailVerifiedAt) }),
+				"pendingEmail":    userField(graphql.String, func(u domain.User) interface{} { return optionalString(u.PendingEmail) }),
+				"lastLoginAt":     userField(graphql.DateTime, func(u domain.User) interface{} { return optionalTime(u.LastLoginAt) }),
+				"lastSeenAt":      userField(graphql.DateTime, func(u domain.User) interface{} { return optionalTime(u.LastSeenAt) }),
+				"lockedUntil":     userField(graphql.DateTime, func(u domain.User) interface{} { return optionalTime(u.LockedUntil) }),
+				"createdAt":       userField(graphql.NewNonNull(graphql.DateTime), func(u domain.User) interface{} { return u.CreatedAt }),
+				"updatedAt":       userField(graphql.NewNonNull(graphql.DateTime), func(u domain.User) interface{} { return u.UpdatedAt }),
+				"avatars": userField(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(avatarType))), func(u domain.User) interface{} {
+					return avatars(u.AvatarURLs)
+				}),
+				"groups": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(groupType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return list(loadersFrom(p.Context).groups.load(p.Context, p.Source.(domain.User).ID)), nil
+					},
+				},
+				"impersonationSessions": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(sessionType))),
+					Description: "Sessions in which an admin impersonated the user, newest first. Requires the admin role.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if err := requireAdmin(p.Context); err != nil {
+							return nil, err
+						}
+						return list(loadersFrom(p.Context).sessions.load(p.Context, p.Source.(domain.User).ID)), nil
+					},
+				},
+			}
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"viewer": &graphql.Field{
+				Type:        userType,
+				Description: "The authenticated user.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					principal, _ := auth.PrincipalFromContext(p.Context)
+					return r.loadUser(p.Context, principal.UserID), nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.loadUser(p.Context, p.Args["id"].(string)), nil
+				},
+			},
+			"userByUsername": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"username": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					user, err := r.uc.GetUserByUsername(p.Context, p.Args["username"].(string))
+					return nullIfNotFound(user, err)
+				},
+			},
+			"users": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType))),
+				Description: "Users, newest first.",
+				Args: graphql.FieldConfigArgument{
+					"first":         &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: r.listSize},
+					"offset":        &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"inactiveSince": &graphql.ArgumentConfig{Type: graphql.DateTime, Description: "Only users without activity since this time."},
+				},
+				Resolve: r.users,
+			},
+			"group": &graphql.Field{
+				Type: groupType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					group, err := r.uc.GetGroup(p.Context, p.Args["id"].(string))
+					return nullIfNotFound(group, err)
+				},
+			},
+			"groups": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(groupType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					groups, err := r.uc.GetAllGroups(p.Context)
+					if groups == nil {
+						groups = []domain.Group{}
+					}
+					return groups, err
+				},
+			},
+		},
+	})
+
+	createUserInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateUserInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"username": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"role":     &graphql.InputObjectFieldConfig{Type: roleEnum},
+			"password": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+	updateUserInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateUserInput",
+		Description: "Fields that are omitted or null are left unchanged. An empty username removes it.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"email":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"name":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"username": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"role":     &graphql.InputObjectFieldConfig{Type: roleEnum},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Creates a user. Requires the admin role.",
+				Args: graphql.FieldConfigArgument{
+					"input":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(createUserInput)},
+					"idempotencyKey": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.createUser,
+			},
+			"updateUser": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Updates a user. Users may update themselves, admins anyone; changing the role requires the admin role.",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateUserInput)},
+				},
+				Resolve: r.updateUser,
+			},
+			"deleteUser": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Deletes a user. Requires the admin role.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requireAdmin(p.Context); err != nil {
+						return nil, err
+					}
+					if err := r.uc.DeleteUser(p.Context, p.Args["id"].(string)); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+			"changeUserStatus": &graphql.Field{
+				Type:        graphql.NewNonNull(userType),
+				Description: "Applies a lifecycle action to a user. Requires the admin role.",
+				Args: graphql.FieldConfigArgument{
+					"id":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"action": &graphql.ArgumentConfig{Type: graphql.NewNonNull(actionEnum)},
+					"reason": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := requireAdmin(p.Context); err != nil {
+						return nil, err
+					}
+					return r.uc.ChangeUserStatus(p.Context, p.Args["id"].(string), p.Args["action"].(string), p.Args["reason"].(string))
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func (r *resolver) users(p graphql.ResolveParams) (interface{}, error) {
+	filter := domain.UserFilter{
+		Limit:  p.Args["first"].(int),
+		Offset: p.Args["offset"].(int),
+	}
+	if filter.Limit <= 0 {
+		return []domain.User{}, nil
+	}
+	if since, ok := p.Args["inactiveSince"].(time.Time); ok {
+		filter.InactiveSince = &since
+	}
+
+	users, err := r.uc.GetAllUsers(p.Context, filter)
+	if users == nil {
+		users = []domain.User{}
+	}
+	return users, err
+}
+
+func (r *resolver) createUser(p graphql.ResolveParams) (interface{}, error) {
+	if err := requireAdmin(p.Context); err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+
+	req := domain.CreateUserRequest{
+		Email: input["email"].(string),
+		Name:  input["name"].(string),
+	}
+	req.Username, _ = input["username"].(string)
+	req.Role, _ = input["role"].(string)
+	req.Password, _ = input["password"].(string)
+	idempotencyKey, _ := p.Args["idempotencyKey"].(string)
+
+	return r.uc.CreateUser(p.Context, idempotencyKey, req)
+}
+
+func (r *resolver) updateUser(p graphql.ResolveParams) (interface{}, error) {
+	id := p.Args["id"].(string)
+	input := p.Args["input"].(map[string]interface{})
+
+	if err := requireSelfOrAdmin(p.Context, id); err != nil {
+		return nil, err
+	}
+
+	var req domain.UpdateUserRequest
+	if email, ok := input["email"].(string); ok {
+		req.Email = &email
+	}
+	if name, ok := input["name"].(string); ok {
+		req.Name = &name
+	}
+	if username, ok := input["username"].(string); ok {
+		req.Username = &username
+	}
+	if role, ok := input["role"].(string); ok {
+		if err := requireAdmin(p.Context); err != nil {
+			return nil, err
+		}
+		req.Role = role
+	}
+
+	return r.uc.UpdateUser(p.Context, id, req)
+}
+
+// loadUser returns a thunk yielding the user or null if it does not exist.
+func (r *resolver) loadUser(ctx context.Context, id string) func() (interface{}, error) {
+	thunk := loadersFrom(ctx).users.load(ctx, id)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil || user.ID == "" {
+			return nil, err
+		}
+		return user, nil
+	}
+}
+
+// list adapts the thunk of a loader to graphql-go, turning missing lists into
+// empty ones.
+func list[T any](thunk func() ([]T, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		items, err := thunk()
+		if items == nil {
+			items = []T{}
+		}
+		return items, err
+	}
+}
+
+func requireAdmin(ctx context.Context) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || principal.Role != domain.RoleAdmin {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// requireSelfOrAdmin lets users act on their own account and admins on any
+// account.
+func requireSelfOrAdmin(ctx context.Context, userID string) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanManage(userID) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func nullIfNotFound(value interface{}, err error) (interface{}, error) {
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func userField(t graphql.Output, get func(domain.User) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(domain.User)), nil
+	}}
+}
+
+func groupField(t graphql.Output, get func(domain.Group) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(domain.Group)), nil
+	}}
+}
+
+func memberField(t graphql.Output, get func(domain.GroupMember) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(domain.GroupMember)), nil
+	}}
+}
+
+func sessionField(t graphql.Output, get func(domain.ImpersonationSession) interface{}) *graphql.Field {
+	return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(domain.ImpersonationSession)), nil
+	}}
+}
+
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func avatars(urls map[string]string) []map[string]interface{} {
+	sizes := make([]string, 0, len(urls))
+	for size := range urls {
+		sizes = append(sizes, size)
+	}
+	slices.Sort(sizes)
+
+	result := make([]map[string]interface{}, 0, len(sizes))
+	for _, size := range sizes {
+		result = append(result, map[string]interface{}{"size": size, "url": urls[size]})
+	}
+	return result
+}
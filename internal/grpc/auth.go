@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	userv1 "github.com/highway-to-Golang/user-service/api/user/v1"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// methodRule describes who may call an RPC.
+type methodRule struct {
+	admin bool
+	write bool
+}
+
+// methodRules mirror the HTTP routes: creating, deleting and listing users
+// needs an admin, reading and updating a user any access token, and the use
+// case restricts updates to the user themselves or an admin. RPCs of the user
+// service missing here need an admin.
+var methodRules = map[string]methodRule{
+	userv1.UserService_CreateUser_FullMethodName: {admin: true, write: true},
+	userv1.UserService_GetUser_FullMethodName:    {},
+	userv1.UserService_UpdateUser_FullMethodName: {write: true},
+	userv1.UserService_DeleteUser_FullMethodName: {admin: true, write: true},
+	userv1.UserService_ListUsers_FullMethodName:  {admin: true},
+}
+
+// authenticator checks the bearer token in the authorization metadata of
+// user service RPCs. The configured service token grants internal callers
+// full access; any other token must be an access token as over HTTP. Health
+// checks and reflection stay open.
+type authenticator struct {
+	uc           *usecase.UseCase
+	serviceToken string
+}
+
+func newAuthenticator(uc *usecase.UseCase, serviceToken string) *authenticator {
+	return &authenticator{uc: uc, serviceToken: serviceToken}
+}
+
+func (a *authenticator) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	rule, ok := methodRules[method]
+	if !ok {
+		if !strings.HasPrefix(method, "/"+userv1.UserService_ServiceDesc.ServiceName+"/") {
+			return ctx, nil
+		}
+		rule = methodRule{admin: true, write: true}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	token, ok := strings.CutPrefix(metadataHeaders(md).Get("authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+
+	if a.serviceToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.serviceToken)) == 1 {
+		return ctx, nil
+	}
+
+	principal, err := a.uc.Authenticate(ctx, token, domain.TokenPurposeAccess)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return nil, toStatus(ctx, "failed to authenticate", err)
+	}
+
+	if rule.admin && principal.Role != domain.RoleAdmin {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+
+	if principal.Impersonated() {
+		if rule.write {
+			if err := a.uc.AuthorizeImpersonatedWrite(ctx, principal, "RPC", method); err != nil {
+				return nil, status.Error(codes.PermissionDenied, "write operations are not allowed while impersonating")
+			}
+		}
+	} else {
+		a.uc.RecordActivity(ctx, principal.UserID)
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
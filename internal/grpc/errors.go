@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/highway-to-Golang/user-service/internal/correlation"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+// errorDomain identifies this service in google.rpc.ErrorInfo details.
+const errorDomain = "user-service"
+
+// toStatus converts a use case error into a gRPC status carrying the same
+// machine-readable code as the problem responses of the HTTP API. Messages of
+// internal errors are logged and never sent to the client.
+func toStatus(ctx context.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	e := apperrors.From(err)
+	code := grpcCode(e)
+	if code == codes.Internal || code == codes.Unavailable {
+		slog.ErrorContext(ctx, message, "error", err, "code", e.Code)
+	}
+
+	st := status.New(code, e.Message)
+
+	info := &errdetails.ErrorInfo{Reason: e.Code, Domain: errorDomain}
+	if ids, ok := correlation.IDsFromContext(ctx); ok {
+		info.Metadata = map[string]string{"request_id": ids.RequestID, "trace_id": ids.TraceID}
+	}
+
+	details := []protoadapt.MessageV1{info}
+	if len(e.Violations) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func grpcCode(e *apperrors.Error) codes.Code {
+	switch {
+	case errors.Is(e, apperrors.ErrConflict):
+		return codes.AlreadyExists
+	case errors.Is(e, apperrors.ErrRequestAlreadyInProgress):
+		return codes.Aborted
+	}
+
+	switch e.Status {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict, http.StatusGone, http.StatusLocked:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests, http.StatusRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
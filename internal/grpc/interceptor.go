@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/highway-to-Golang/user-service/internal/correlation"
+)
+
+// requestIDUnaryInterceptor is the gRPC counterpart of the HTTP
+// RequestIDMiddleware. IDs are read from the x-request-id and traceparent
+// metadata, and the request ID is returned in the x-request-id header.
+func requestIDUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ids := requestIDs(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(correlation.RequestIDHeader), ids.RequestID))
+
+	return handler(correlation.WithIDs(ctx, ids), req)
+}
+
+func requestIDStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ids := requestIDs(ss.Context())
+	_ = ss.SetHeader(metadata.Pairs(strings.ToLower(correlation.RequestIDHeader), ids.RequestID))
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: correlation.WithIDs(ss.Context(), ids)})
+}
+
+func requestIDs(ctx context.Context) correlation.IDs {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return correlation.FromHeaders(metadataHeaders(md))
+}
+
+// metadataHeaders adapts incoming metadata to correlation.Headers.
+type metadataHeaders metadata.MD
+
+func (h metadataHeaders) Get(key string) string {
+	if values := metadata.MD(h).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func loggingUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	slog.InfoContext(ctx, "incoming rpc", "method", info.FullMethod)
+
+	resp, err := handler(ctx, req)
+
+	slog.InfoContext(ctx, "rpc completed",
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	)
+	return resp, err
+}
+
+func loggingStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	slog.InfoContext(ss.Context(), "incoming rpc", "method", info.FullMethod)
+
+	err := handler(srv, ss)
+
+	slog.InfoContext(ss.Context(), "rpc completed",
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start),
+	)
+	return err
+}
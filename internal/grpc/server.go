@@ -0,0 +1,98 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	userv1 "github.com/highway-to-Golang/user-service/api/user/v1"
+	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+type Server struct {
+	grpcServer *grpc.Server
+	health     *health.Server
+	addr       string
+	listener   net.Listener
+}
+
+// NewServer registers the user service together with the standard health
+// checking and reflection services.
+func NewServer(cfg config.Config, uc *usecase.UseCase) *Server {
+	authenticator := newAuthenticator(uc, cfg.GRPC.ServiceToken)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestIDUnaryInterceptor, loggingUnaryInterceptor, authenticator.unary),
+		grpc.ChainStreamInterceptor(requestIDStreamInterceptor, loggingStreamInterceptor, authenticator.stream),
+	)
+
+	userv1.RegisterUserServiceServer(grpcServer, NewUserService(uc))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(userv1.UserService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	reflection.Register(grpcServer)
+
+	return &Server{
+		grpcServer: grpcServer,
+		health:     healthServer,
+		addr:       fmt.Sprintf("%s:%s", cfg.GRPC.Host, cfg.GRPC.Port),
+	}
+}
+
+// Listen binds the address of the server, so that a port that is in use fails
+// the startup instead of the background Serve.
+func (s *Server) Listen() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	s.listener = listener
+
+	return nil
+}
+
+// Serve serves RPCs on the listener bound by Listen until Shutdown.
+func (s *Server) Serve() error {
+	slog.Info("starting gRPC server", "address", s.listener.Addr().String())
+
+	if err := s.grpcServer.Serve(s.listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown reports NOT_SERVING to health checks and waits for in-flight RPCs
+// to finish. Streams still running when ctx is done are cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	slog.InfoContext(ctx, "shutting down gRPC server")
+
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return fmt.Errorf("failed to shutdown server: %w", ctx.Err())
+	}
+
+	slog.InfoContext(ctx, "gRPC server stopped")
+	return nil
+}
@@ -0,0 +1,134 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	userv1 "github.com/highway-to-Golang/user-service/api/user/v1"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// UserService implements userv1.UserServiceServer on top of the same use case
+// as the HTTP handlers.
+type UserService struct {
+	userv1.UnimplementedUserServiceServer
+
+	uc *usecase.UseCase
+}
+
+func NewUserService(uc *usecase.UseCase) *UserService {
+	return &UserService{uc: uc}
+}
+
+func (s *UserService) CreateUser(ctx context.Context, req *userv1.CreateUserRequest) (*userv1.CreateUserResponse, error) {
+	user, err := s.uc.CreateUser(ctx, req.GetIdempotencyKey(), domain.CreateUserRequest{
+		Email:    req.GetEmail(),
+		Name:     req.GetName(),
+		Username: req.GetUsername(),
+		Role:     req.GetRole(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, "failed to create user", err)
+	}
+
+	return &userv1.CreateUserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.GetUserResponse, error) {
+	user, err := s.uc.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, "failed to get user", err)
+	}
+
+	return &userv1.GetUserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.UpdateUserResponse, error) {
+	update := domain.UpdateUserRequest{
+		Email:    req.Email,
+		Name:     req.Name,
+		Username: req.Username,
+		Role:     req.GetRole(),
+	}
+
+	user, err := s.uc.UpdateUser(ctx, req.GetId(), update)
+	if err != nil {
+		return nil, toStatus(ctx, "failed to update user", err)
+	}
+
+	return &userv1.UpdateUserResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*userv1.DeleteUserResponse, error) {
+	if err := s.uc.DeleteUser(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, "failed to delete user", err)
+	}
+
+	return &userv1.DeleteUserResponse{}, nil
+}
+
+// listUsersPageSize is the number of users ListUsers loads at a time, so that
+// large user bases are streamed instead of loaded at once.
+const listUsersPageSize = 500
+
+func (s *UserService) ListUsers(req *userv1.ListUsersRequest, stream grpc.ServerStreamingServer[userv1.ListUsersResponse]) error {
+	ctx := stream.Context()
+
+	var filter domain.UserFilter
+	if req.InactiveSince != nil {
+		since := req.GetInactiveSince().AsTime()
+		filter.InactiveSince = &since
+	}
+
+	filter.Limit = listUsersPageSize
+	for {
+		users, err := s.uc.GetAllUsers(ctx, filter)
+		if err != nil {
+			return toStatus(ctx, "failed to list users", err)
+		}
+
+		for _, user := range users {
+			if err := stream.Send(&userv1.ListUsersResponse{User: toProtoUser(user)}); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < filter.Limit {
+			return nil
+		}
+		filter.Offset += len(users)
+	}
+}
+
+func toProtoUser(user domain.User) *userv1.User {
+	return &userv1.User{
+		Id:              user.ID,
+		Name:            user.Name,
+		Username:        user.Username,
+		Email:           user.Email,
+		Role:            user.Role,
+		Status:          user.Status,
+		StatusReason:    user.StatusReason,
+		StatusChangedAt: toTimestamp(user.StatusChangedAt),
+		EmailVerifiedAt: toTimestamp(user.EmailVerifiedAt),
+		PendingEmail:    user.PendingEmail,
+		AvatarUrls:      user.AvatarURLs,
+		LastLoginAt:     toTimestamp(user.LastLoginAt),
+		LastSeenAt:      toTimestamp(user.LastSeenAt),
+		LockedUntil:     toTimestamp(user.LockedUntil),
+		CreatedAt:       timestamppb.New(user.CreatedAt),
+		UpdatedAt:       timestamppb.New(user.UpdatedAt),
+	}
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
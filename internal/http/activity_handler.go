@@ -0,0 +1,56 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+// InactivityReport returns what the inactivity check would do right now
+// without changing any user.
+func (h *UserHandler) InactivityReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.uc.CheckInactivity(r.Context(), true)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to check inactivity", "error", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// userFilterFromQuery parses the user list filters. Inactivity is given either
+// as inactive_days or as an RFC 3339 inactive_since timestamp.
+func userFilterFromQuery(query url.Values) (domain.UserFilter, error) {
+	var filter domain.UserFilter
+
+	if v := query.Get("inactive_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 0 {
+			return domain.UserFilter{}, apperrors.Validation("invalid filter",
+				apperrors.Violation{Field: "inactive_days", Message: "must be a non-negative number"})
+		}
+		since := time.Now().AddDate(0, 0, -days)
+		filter.InactiveSince = &since
+	}
+
+	if v := query.Get("inactive_since"); v != "" {
+		if filter.InactiveSince != nil {
+			return domain.UserFilter{}, apperrors.Validation("invalid filter",
+				apperrors.Violation{Field: "inactive_since", Message: "cannot be combined with inactive_days"})
+		}
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return domain.UserFilter{}, apperrors.Validation("invalid filter",
+				apperrors.Violation{Field: "inactive_since", Message: "must be an RFC 3339 timestamp"})
+		}
+		filter.InactiveSince = &since
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/config"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+// AdminHandler serves the admin endpoints: build info, the redacted
+// configuration and the runtime log level.
+type AdminHandler struct {
+	cfg      config.Config
+	logLevel *slog.LevelVar
+}
+
+func NewAdminHandler(cfg config.Config, logLevel *slog.LevelVar) *AdminHandler {
+	return &AdminHandler{cfg: cfg, logLevel: logLevel}
+}
+
+// NewAdminServer returns the admin server, which listens separately from the
+// API on cfg.Admin and requires the admin token on every request. Profiles
+// can take longer than API requests, so responses have no write timeout.
+func NewAdminServer(cfg config.Config, logLevel *slog.LevelVar) *Server {
+	handler := NewAdminHandler(cfg, logLevel)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("GET /admin/build", handler.BuildInfo)
+	mux.HandleFunc("GET /admin/config", handler.Config)
+	mux.HandleFunc("GET /admin/log-level", handler.LogLevel)
+	mux.HandleFunc("PUT /admin/log-level", handler.SetLogLevel)
+
+	var h http.Handler = AdminAuthMiddleware(cfg.Admin.Token)(mux)
+	h = LoggingMiddleware(h)
+	h = RequestIDMiddleware(h)
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:        fmt.Sprintf("%s:%s", cfg.Admin.Host, cfg.Admin.Port),
+			Handler:     h,
+			ReadTimeout: 10 * time.Second,
+			IdleTimeout: 60 * time.Second,
+		},
+		name: "admin",
+	}
+}
+
+// AdminAuthMiddleware requires the static admin token as bearer token. It is
+// separate from user tokens, so that the admin server does not depend on the
+// database.
+func AdminAuthMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || bearer == "" {
+				writeError(w, r, http.StatusUnauthorized, "Missing bearer token")
+				return
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				writeError(w, r, http.StatusUnauthorized, "Invalid token")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type buildInfoResponse struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+}
+
+// BuildInfo returns the Go version, module version and the VCS and build
+// settings embedded in the binary.
+func (h *AdminHandler) BuildInfo(w http.ResponseWriter, r *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "Build info is not available")
+		return
+	}
+
+	resp := buildInfoResponse{
+		GoVersion: info.GoVersion,
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+		Settings:  make(map[string]string, len(info.Settings)),
+	}
+	for _, setting := range info.Settings {
+		resp.Settings[setting.Key] = setting.Value
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Config returns the effective configuration with secrets redacted.
+func (h *AdminHandler) Config(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.cfg.Redacted())
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+func (h *AdminHandler) LogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: h.logLevel.Level().String()})
+}
+
+// SetLogLevel changes the level of the default logger until the next restart,
+// e.g. to "debug".
+func (h *AdminHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+		writeProblem(w, r, apperrors.Validation("invalid log level",
+			apperrors.Violation{Field: "level", Message: "must be debug, info, warn or error"}))
+		return
+	}
+
+	previous := h.logLevel.Level()
+	h.logLevel.Set(level)
+	slog.WarnContext(r.Context(), "log level changed", "from", previous, "to", level)
+
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: level.String()})
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req domain.LoginRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.IP = clientIP(r)
+
+	result, err := h.uc.Login(r.Context(), req)
+	if err != nil {
+		writeLoginError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *UserHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req domain.LoginMFARequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.IP = clientIP(r)
+
+	result, err := h.uc.LoginMFA(r.Context(), req)
+	if err != nil {
+		writeLoginError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// writeLoginError renders a failed login. Locked accounts (423) and backoff
+// (429) also get a Retry-After header.
+func writeLoginError(w http.ResponseWriter, r *http.Request, err error) {
+	var throttled *domain.ThrottledError
+	if errors.As(err, &throttled) {
+		retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+
+	writeProblem(w, r, err)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func (h *UserHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	enrollment, err := h.uc.EnrollMFA(r.Context(), principal.UserID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, enrollment)
+}
+
+func (h *UserHandler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req domain.MFAConfirmRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	confirmation, err := h.uc.ConfirmMFA(r.Context(), principal.UserID, req.Code)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, confirmation)
+}
+
+func (h *UserHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	var req domain.MFADisableRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.uc.DisableMFA(r.Context(), principal.UserID, req.Code); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "MFA disabled successfully",
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) ResetMFA(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	if err := h.uc.ResetMFA(r.Context(), id, principal.UserID); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "MFA reset successfully",
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	user, err := h.uc.UnlockUser(r.Context(), id, principal.UserID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
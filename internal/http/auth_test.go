@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+// TestAuthUsesCurrentUser checks that tokens issued before a suspension or a
+// demotion stop granting access at once.
+func TestAuthUsesCurrentUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		role       string
+		wantStatus int
+	}{
+		{"active admin", domain.StatusActive, domain.RoleAdmin, http.StatusOK},
+		{"suspended admin", domain.StatusSuspended, domain.RoleAdmin, http.StatusUnauthorized},
+		{"disabled admin", domain.StatusDisabled, domain.RoleAdmin, http.StatusUnauthorized},
+		{"deleted admin", domain.StatusDeleted, domain.RoleAdmin, http.StatusUnauthorized},
+		{"demoted admin", domain.StatusActive, domain.RoleUser, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+
+			env.users.mu.Lock()
+			admin := env.users.users["admin"]
+			admin.Status = tt.status
+			admin.Role = tt.role
+			env.users.users["admin"] = admin
+			env.users.mu.Unlock()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/oidc/clients", nil)
+			req.Header.Set("Authorization", "Bearer "+env.adminToken)
+
+			if rec := env.do(req); rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
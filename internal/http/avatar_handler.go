@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+)
+
+// avatarFormField is the multipart field carrying the image.
+const avatarFormField = "avatar"
+
+// multipartOverhead allows for boundaries and headers around the image.
+const multipartOverhead = 64 << 10
+
+func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	limit := h.uc.AvatarUploadLimit()
+	r.Body = http.MaxBytesReader(w, r.Body, limit+multipartOverhead)
+
+	data, err := readAvatarPart(r, limit)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr), errors.Is(err, errAvatarTooLarge):
+			writeError(w, r, http.StatusRequestEntityTooLarge, "Avatar is too large")
+		default:
+			slog.WarnContext(r.Context(), "invalid avatar upload", "error", err)
+			writeError(w, r, http.StatusBadRequest, "Invalid multipart upload, expected an \""+avatarFormField+"\" file")
+		}
+		return
+	}
+
+	user, err := h.uc.UploadAvatar(r.Context(), id, data)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *UserHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	if err := h.uc.DeleteAvatar(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServeAvatar serves a stored avatar variant. Avatar URLs are versioned, so
+// responses can be cached forever.
+func (h *UserHandler) ServeAvatar(w http.ResponseWriter, r *http.Request) {
+	version := r.PathValue("version")
+	name := r.PathValue("name")
+
+	// Open the object before answering a conditional request, so that a
+	// deleted or replaced avatar is not reported as unchanged.
+	object, err := h.uc.OpenAvatar(r.Context(), r.PathValue("id"), version, name)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+	defer object.Body.Close()
+
+	etag := `"` + version + "-" + name + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", object.ModTime.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, object.Body); err != nil {
+		slog.WarnContext(r.Context(), "failed to write avatar", "error", err)
+	}
+}
+
+var errAvatarTooLarge = errors.New("avatar too large")
+
+// readAvatarPart streams the multipart body to the avatar file without
+// buffering other parts on disk.
+func readAvatarPart(r *http.Request, limit int64) ([]byte, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() != avatarFormField {
+			_ = part.Close()
+			continue
+		}
+
+		return readLimited(part, limit)
+	}
+}
+
+func readLimited(part *multipart.Part, limit int64) ([]byte, error) {
+	defer part.Close()
+
+	data, err := io.ReadAll(io.LimitReader(part, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errAvatarTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeAvatarChecksExistenceFirst(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/avatars/alice/v1/256.png", nil)
+	req.Header.Set("If-None-Match", `"v1-256.png"`)
+
+	if rec := env.do(req); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestUploadAvatarRejectsMalformedMultipart(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"not multipart", "application/json", `{}`},
+		{"missing boundary", "multipart/form-data", "--x\r\n"},
+		{"truncated", "multipart/form-data; boundary=x", "--x\r\nContent-Disposition: form-data; name=\"avatar\"\r\n"},
+		{"no avatar field", "multipart/form-data; boundary=x", "--x\r\nContent-Disposition: form-data; name=\"other\"\r\n\r\nhi\r\n--x--\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/alice/avatar", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+env.userToken)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			if rec := env.do(req); rec.Code != http.StatusBadRequest {
+				t.Errorf("got status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req domain.VerifyEmailRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.uc.VerifyEmail(r.Context(), req.Token)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// RequestEmailVerification resends the verification link. Users may only
+// request it for themselves; admins for anyone.
+func (h *UserHandler) RequestEmailVerification(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	if err := h.uc.RequestEmailVerification(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "Verification email sent",
+	}
+
+	writeJSON(w, http.StatusAccepted, response)
+}
+
+func (h *UserHandler) ListUserEmails(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	emails, err := h.uc.ListUserEmails(r.Context(), id)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	if emails == nil {
+		emails = []domain.UserEmail{}
+	}
+
+	writeJSON(w, http.StatusOK, emails)
+}
+
+func (h *UserHandler) AddUserEmail(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	var req domain.AddUserEmailRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	email, err := h.uc.AddUserEmail(r.Context(), id, req.Email)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, email)
+}
+
+func (h *UserHandler) RemoveUserEmail(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	if err := h.uc.RemoveUserEmail(r.Context(), id, r.PathValue("email")); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) PromoteUserEmail(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	user, err := h.uc.PromoteUserEmail(r.Context(), id, r.PathValue("email"))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+// authorizeSelfOrAdmin lets users act on their own account and admins on any
+// account. It writes a 403 response otherwise.
+func authorizeSelfOrAdmin(w http.ResponseWriter, r *http.Request, userID string) bool {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	if !principal.CanManage(userID) {
+		writeError(w, r, http.StatusForbidden, "Forbidden")
+		return false
+	}
+	return true
+}
@@ -3,8 +3,11 @@ package http
 import (
 	"encoding/json"
 	"errors"
-	"log/slog"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/highway-to-Golang/user-service/internal/domain"
 	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
@@ -29,31 +32,53 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	}
 }
 
-func writeErrorJSON(w http.ResponseWriter, status int, message string) {
-	errorResponse := map[string]interface{}{
-		"error": message,
+// maxBodyBytes limits the size of request bodies.
+const maxBodyBytes = 1 << 20
+
+// decodeJSON strictly decodes the JSON request body into dst. Unknown fields,
+// trailing data and bodies larger than maxBodyBytes are rejected. On failure
+// it writes the error response and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("unexpected data after the JSON object")
+	}
+	if err == nil {
+		return true
 	}
-	writeJSON(w, status, errorResponse)
+
+	var maxBytesErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", maxBodyBytes))
+	case errors.As(err, &typeErr):
+		writeProblem(w, r, apperrors.Validation("invalid request body",
+			apperrors.Violation{Field: typeErr.Field, Message: "must be of type " + typeErr.Type.String()}))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		writeProblem(w, r, apperrors.Validation("invalid request body",
+			apperrors.Violation{Field: field, Message: "is not allowed"}))
+	default:
+		writeError(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+	}
+	return false
 }
 
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	idempotencyKey := r.Header.Get("Idempotency-Key")
 
 	var req domain.CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("failed to decode request body", "error", err)
-		writeErrorJSON(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	user, err := h.uc.CreateUser(r.Context(), idempotencyKey, req)
 	if err != nil {
-		if errors.Is(err, apperrors.ErrRequestAlreadyInProgress) {
-			writeErrorJSON(w, http.StatusUnprocessableEntity, "Request already in progress")
-			return
-		}
-		slog.Error("failed to create user", "error", err)
-		writeErrorJSON(w, http.StatusUnprocessableEntity, "Failed to create user")
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -65,12 +90,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.uc.GetUser(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			writeErrorJSON(w, http.StatusNotFound, "User not found")
-			return
-		}
-		slog.Error("failed to get user", "error", err, "user_id", id)
-		writeErrorJSON(w, http.StatusUnprocessableEntity, "Failed to get user")
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -78,10 +98,15 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.uc.GetAllUsers(r.Context())
+	filter, err := userFilterFromQuery(r.URL.Query())
 	if err != nil {
-		slog.Error("failed to get users", "error", err)
-		writeErrorJSON(w, http.StatusUnprocessableEntity, "Failed to get users")
+		writeProblem(w, r, err)
+		return
+	}
+
+	users, err := h.uc.GetAllUsers(r.Context(), filter)
+	if err != nil {
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -93,24 +118,22 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+// UpdateUser updates the user itself or, for admins, any user. Only admins may
+// change roles.
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
 
 	var req domain.UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("failed to decode request body", "error", err)
-		writeErrorJSON(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	user, err := h.uc.UpdateUser(r.Context(), id, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			writeErrorJSON(w, http.StatusNotFound, "User not found")
-			return
-		}
-		slog.Error("failed to update user", "error", err, "user_id", id)
-		writeErrorJSON(w, http.StatusUnprocessableEntity, "Failed to update user")
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -121,12 +144,7 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 
 	if err := h.uc.DeleteUser(r.Context(), id); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			writeErrorJSON(w, http.StatusNotFound, "User not found")
-			return
-		}
-		slog.Error("failed to delete user", "error", err, "user_id", id)
-		writeErrorJSON(w, http.StatusUnprocessableEntity, "Failed to delete user")
+		writeProblem(w, r, err)
 		return
 	}
 
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"object", `{"name":"Ann"}`, 0},
+		{"trailing whitespace", "{\"name\":\"Ann\"}\n", 0},
+		{"second object", `{"name":"Ann"}{"name":"Bob"}`, http.StatusBadRequest},
+		{"trailing garbage", `{"name":"Ann"} x`, http.StatusBadRequest},
+		{"trailing bracket", `{"name":"Ann"}]`, http.StatusBadRequest},
+		{"unknown field", `{"nickname":"Ann"}`, http.StatusBadRequest},
+		{"too large", `{"name":"` + strings.Repeat("a", maxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+
+			var dst struct {
+				Name string `json:"name"`
+			}
+			ok := decodeJSON(w, r, &dst)
+
+			if tt.wantStatus == 0 {
+				if !ok {
+					t.Fatalf("decodeJSON failed with %d: %s", w.Code, w.Body)
+				}
+				return
+			}
+			if ok {
+				t.Fatal("decodeJSON succeeded, want an error")
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
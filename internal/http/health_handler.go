@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+// Liveness reports that the process serves requests. It does not check
+// dependencies, so that their outages do not get the service restarted.
+func (h *UserHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": domain.HealthStatusOK})
+}
+
+// Readiness reports whether the service should receive traffic, with the
+// result of every dependency check. Unready services respond with 503.
+func (h *UserHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.uc.CheckReadiness(r.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, report)
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+func (h *UserHandler) impersonate(w http.ResponseWriter, r *http.Request, id string) {
+	var req domain.ImpersonateRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	req.IP = clientIP(r)
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	result, err := h.uc.Impersonate(r.Context(), principal, id, req)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *UserHandler) ListImpersonationSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.uc.ListImpersonationSessions(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	if sessions == nil {
+		sessions = []domain.ImpersonationSession{}
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+func (h *UserHandler) CreateInvitation(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateInvitationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	invitation, err := h.uc.CreateInvitation(r.Context(), req, principal.UserID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, invitation)
+}
+
+func (h *UserHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.uc.ListInvitations(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	if invitations == nil {
+		invitations = []domain.Invitation{}
+	}
+
+	writeJSON(w, http.StatusOK, invitations)
+}
+
+func (h *UserHandler) GetInvitation(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.uc.GetInvitation(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, invitation)
+}
+
+func (h *UserHandler) ResendInvitation(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	invitation, err := h.uc.ResendInvitation(r.Context(), r.PathValue("id"), principal.UserID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, invitation)
+}
+
+func (h *UserHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	invitation, err := h.uc.RevokeInvitation(r.Context(), r.PathValue("id"), principal.UserID)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, invitation)
+}
+
+func (h *UserHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req domain.AcceptInvitationRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.uc.AcceptInvitation(r.Context(), req)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
@@ -1,9 +1,34 @@
 package http
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/correlation"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+	"github.com/highway-to-Golang/user-service/internal/metrics"
+	"github.com/highway-to-Golang/user-service/internal/openapi"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
 )
 
 type responseWriter struct {
@@ -16,6 +41,20 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// RequestIDMiddleware takes the request and trace IDs from the X-Request-ID
+// and traceparent headers, or generates them, and stores them in the request
+// context for logs, error responses and events. The request ID is echoed in
+// the X-Request-ID response header. Inside TracingMiddleware the trace and
+// span IDs are those of the server span.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := correlation.FromHeaders(r.Header).WithSpan(trace.SpanContextFromContext(r.Context()))
+		w.Header().Set(correlation.RequestIDHeader, ids.RequestID)
+
+		next.ServeHTTP(w, r.WithContext(correlation.WithIDs(r.Context(), ids)))
+	})
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -25,7 +64,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 			statusCode:     http.StatusOK,
 		}
 
-		slog.Info("incoming request",
+		slog.InfoContext(r.Context(), "incoming request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"remote_addr", r.RemoteAddr,
@@ -34,7 +73,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(wrapped, r)
 
 		duration := time.Since(start)
-		slog.Info("request completed",
+		slog.InfoContext(r.Context(), "request completed",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", wrapped.statusCode,
@@ -42,3 +81,349 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		)
 	})
 }
+
+// MetricsMiddleware counts requests and observes their latency by method, the
+// pattern of the route of mux they matched, e.g. "/api/users/{id}", and
+// status. Requests that match no route share the route "unmatched" and
+// unknown methods the method "other", so that arbitrary paths and methods
+// cannot create new series.
+func MetricsMiddleware(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			route := routePattern(mux, r)
+
+			wrapped := &responseWriter{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+
+			next.ServeHTTP(wrapped, r)
+
+			method := metricsMethod(r.Method)
+			status := strconv.Itoa(wrapped.statusCode)
+			metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// metricsMethod returns method if it is a standard HTTP method, or "other".
+func metricsMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+// routePattern returns the path pattern of the route of mux that r matches,
+// or "unmatched".
+func routePattern(mux *http.ServeMux, r *http.Request) string {
+	if _, pattern := mux.Handler(r); pattern != "" {
+		_, route, _ := strings.Cut(pattern, " ")
+		return route
+	}
+	return "unmatched"
+}
+
+// TracingMiddleware starts a server span for every request, continuing the
+// trace of the traceparent header if there is one. Responses with a 5xx
+// status mark the span as failed.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := tracing.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		wrapped := &responseWriter{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+
+		next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(wrapped.statusCode))
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+		}
+	})
+}
+
+// SpanRouteMiddleware names the server span after the route of mux the
+// request matched, e.g. "GET /api/users/{id}", which TracingMiddleware cannot
+// know as it runs before routing.
+func SpanRouteMiddleware(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routePattern(mux, r)
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthMiddleware requires a bearer token issued for one of the given purposes
+// and stores the resulting principal in the request context. Requests with
+// other methods than GET, HEAD and OPTIONS are writes and need
+// UseCase.AuthorizeImpersonatedWrite during impersonation.
+func AuthMiddleware(uc *usecase.UseCase, purposes ...string) func(http.Handler) http.Handler {
+	return authMiddleware(uc, true, purposes...)
+}
+
+// OperationAuthMiddleware is AuthMiddleware for handlers that tell reads from
+// writes by the operation rather than the method, such as GraphQL, where
+// queries are sent with POST. The handler must authorize impersonated writes
+// itself.
+func OperationAuthMiddleware(uc *usecase.UseCase, purposes ...string) func(http.Handler) http.Handler {
+	return authMiddleware(uc, false, purposes...)
+}
+
+func authMiddleware(uc *usecase.UseCase, checkWrites bool, purposes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				writeError(w, r, http.StatusUnauthorized, "Missing bearer token")
+				return
+			}
+
+			principal, err := uc.Authenticate(r.Context(), token, purposes...)
+			if err != nil {
+				if errors.Is(err, domain.ErrUnauthorized) {
+					writeError(w, r, http.StatusUnauthorized, "Invalid token")
+					return
+				}
+				writeProblem(w, r, err)
+				return
+			}
+
+			if principal.Impersonated() {
+				w.Header().Set("X-Impersonated-By", principal.ActorID)
+				if checkWrites && !isReadOnlyMethod(r.Method) {
+					if err := uc.AuthorizeImpersonatedWrite(r.Context(), principal, r.Method, r.URL.Path); err != nil {
+						writeError(w, r, http.StatusForbidden, "Write operations are not allowed while impersonating")
+						return
+					}
+				}
+			} else {
+				uc.RecordActivity(r.Context(), principal.UserID)
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// RequireRole rejects requests whose principal does not have one of the given
+// roles. It must be wrapped by AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFromContext(r.Context())
+			if !ok || !slices.Contains(roles, principal.Role) {
+				writeError(w, r, http.StatusForbidden, "Forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitExempt lists the routes that are never rate limited, so that
+// orchestrator probes keep working while a client is throttled.
+var rateLimitExempt = map[string]bool{
+	"GET /healthz": true,
+	"GET /readyz":  true,
+}
+
+// RateLimitMiddleware limits requests per client IP, per API key and, for the
+// routes of mux listed in cfg.Routes, per route and client IP. API keys are
+// not validated here, so route limits do not use them: a client sending a new
+// made-up key with every request would otherwise get a fresh bucket each time.
+// Responses carry the RateLimit-* headers of the most restrictive limit, and
+// rejected requests get a 429 problem with a Retry-After header. Health probes
+// are exempt.
+func RateLimitMiddleware(uc *usecase.UseCase, cfg config.RateLimit, mux *http.ServeMux) func(http.Handler) http.Handler {
+	if !cfg.Enabled {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	routes := make(map[string]int, len(cfg.Routes))
+	for pattern, requests := range cfg.Routes {
+		routes[strings.TrimSpace(pattern)] = requests
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := mux.Handler(r)
+			if rateLimitExempt[pattern] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client := "ip:" + clientIP(r)
+
+			var limits []domain.RateLimit
+			if cfg.IP > 0 {
+				limits = append(limits, domain.RateLimit{Key: client, Requests: cfg.IP, Period: cfg.Window})
+			}
+			if key := apiKey(r); key != "" && cfg.APIKey > 0 {
+				limits = append(limits, domain.RateLimit{Key: "key:" + key, Requests: cfg.APIKey, Period: cfg.Window})
+			}
+			if routes[pattern] > 0 {
+				limits = append(limits, domain.RateLimit{Key: "route:" + pattern + ":" + client, Requests: routes[pattern], Period: cfg.Window})
+			}
+
+			decision := uc.AllowRequest(r.Context(), limits)
+			if len(limits) > 0 {
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit.Requests))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+				w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+				w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit.Requests, ceilSeconds(decision.Limit.Period)))
+			}
+			if !decision.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(decision.RetryAfter), 1)))
+				writeError(w, r, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKey identifies the credential of a request, sent in the X-API-Key or the
+// Authorization header, by its hash, so that credentials never end up in
+// Redis.
+func apiKey(r *http.Request) string {
+	credential := r.Header.Get("X-API-Key")
+	if credential == "" {
+		credential, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if credential == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:16])
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// OpenAPIValidationMiddleware checks requests and responses against the
+// OpenAPI document. Invalid requests are rejected before they reach a handler,
+// and responses that do not match the document, as well as successful
+// responses of undocumented routes, are replaced by a 500 problem listing the
+// violations, so that drift between the handlers and the document fails
+// tests. Request bodies larger than maxBytes are rejected. Responses are
+// buffered, so it must not be used in production.
+func OpenAPIValidationMiddleware(spec *openapi.Spec, maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := newResponseRecorder()
+
+			op, err := spec.Find(r.Method, r.URL.Path)
+			if err != nil {
+				next.ServeHTTP(recorder, r)
+				if recorder.status != http.StatusNotFound && recorder.status != http.StatusMethodNotAllowed {
+					writeProblem(w, r, &apperrors.Error{
+						Code:    "spec_violation",
+						Status:  http.StatusInternalServerError,
+						Message: err.Error(),
+						Err:     openapi.ErrSpecViolation,
+					})
+					return
+				}
+				recorder.flush(w)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", maxBytes))
+					return
+				}
+				writeError(w, r, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if err := spec.ValidateRequest(op, r, body); err != nil {
+				writeProblem(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(recorder, r)
+
+			if err := spec.ValidateResponse(op, recorder.status, recorder.header, recorder.body.Bytes()); err != nil {
+				writeProblem(w, r, err)
+				return
+			}
+			recorder.flush(w)
+		})
+	}
+}
+
+// responseRecorder buffers a response so that it can be inspected before it
+// is sent.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = code
+	rec.wroteHeader = true
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.body.Write(b)
+}
+
+func (rec *responseRecorder) flush(w http.ResponseWriter) {
+	for key, values := range rec.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(rec.status)
+	if _, err := w.Write(rec.body.Bytes()); err != nil {
+		slog.Error("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+func (h *UserHandler) OIDCDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.uc.OIDCDiscovery())
+}
+
+func (h *UserHandler) OIDCJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	writeJSON(w, http.StatusOK, h.uc.OIDCJWKS())
+}
+
+// OIDCAuthorize handles the authorization endpoint. The end user is
+// authenticated with a bearer access token obtained from /api/auth/login.
+func (h *UserHandler) OIDCAuthorize(w http.ResponseWriter, r *http.Request) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	query := r.URL.Query()
+
+	req := domain.OIDCAuthorizationRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+
+	location, err := h.uc.AuthorizeOIDC(r.Context(), principal, req)
+	if err != nil {
+		var oidcErr *domain.OIDCError
+		switch {
+		case errors.As(err, &oidcErr):
+			http.Redirect(w, r, usecase.OIDCErrorRedirect(req.RedirectURI, req.State, oidcErr), http.StatusFound)
+		case errors.Is(err, domain.ErrInvalidInput):
+			writeJSON(w, http.StatusBadRequest, domain.OIDCError{Code: "invalid_request", Description: err.Error()})
+		default:
+			slog.ErrorContext(r.Context(), "failed to authorize oidc request", "error", err, "client_id", req.ClientID)
+			http.Redirect(w, r, usecase.OIDCErrorRedirect(req.RedirectURI, req.State, &domain.OIDCError{Code: "server_error"}), http.StatusFound)
+		}
+		return
+	}
+
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+func (h *UserHandler) OIDCToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, domain.OIDCError{Code: "invalid_request"})
+		return
+	}
+
+	req := domain.OIDCTokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+	}
+	if id, secret, ok := r.BasicAuth(); ok {
+		req.ClientID = id
+		req.ClientSecret = secret
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	response, err := h.uc.ExchangeOIDCCode(r.Context(), req)
+	if err != nil {
+		var oidcErr *domain.OIDCError
+		if errors.As(err, &oidcErr) {
+			status := http.StatusBadRequest
+			if oidcErr.Code == "invalid_client" {
+				status = http.StatusUnauthorized
+			}
+			writeJSON(w, status, oidcErr)
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to exchange oidc code", "error", err, "client_id", req.ClientID)
+		writeJSON(w, http.StatusInternalServerError, domain.OIDCError{Code: "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) OIDCUserInfo(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		writeError(w, r, http.StatusUnauthorized, "Missing bearer token")
+		return
+	}
+
+	info, err := h.uc.OIDCUserInfo(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		}
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+func (h *UserHandler) RegisterOIDCClient(w http.ResponseWriter, r *http.Request) {
+	var req domain.RegisterOIDCClientRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	client, err := h.uc.RegisterOIDCClient(r.Context(), req)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, client)
+}
+
+func (h *UserHandler) ListOIDCClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := h.uc.ListOIDCClients(r.Context())
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"clients": clients,
+		"total":   len(clients),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) DeleteOIDCClient(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := h.uc.DeleteOIDCClient(r.Context(), id); err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"message": "Client deleted successfully",
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
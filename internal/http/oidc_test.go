@@ -0,0 +1,512 @@
+package http
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+const (
+	testIssuer      = "http://issuer.test"
+	testRedirectURI = "http://client.test/callback"
+)
+
+// memoryUsers implements the part of usecase.Repository used by the OIDC
+// flow.
+type memoryUsers struct {
+	usecase.Repository
+
+	mu    sync.Mutex
+	users map[string]domain.User
+}
+
+func (m *memoryUsers) GetByID(_ context.Context, id string) (domain.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return domain.User{}, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+type memoryOIDC struct {
+	mu      sync.Mutex
+	clients map[string]domain.OIDCClient
+	codes   map[string]domain.OIDCAuthorizationCode
+}
+
+func (m *memoryOIDC) CreateClient(_ context.Context, client domain.OIDCClient) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clients[client.ID] = client
+	return nil
+}
+
+func (m *memoryOIDC) GetClient(_ context.Context, id string) (domain.OIDCClient, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	client, ok := m.clients[id]
+	if !ok {
+		return domain.OIDCClient{}, domain.ErrNotFound
+	}
+	return client, nil
+}
+
+func (m *memoryOIDC) ListClients(_ context.Context) ([]domain.OIDCClient, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clients := make([]domain.OIDCClient, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (m *memoryOIDC) DeleteClient(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.clients[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(m.clients, id)
+	return nil
+}
+
+func (m *memoryOIDC) SaveCode(_ context.Context, code domain.OIDCAuthorizationCode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.codes[code.CodeHash] = code
+	return nil
+}
+
+func (m *memoryOIDC) ConsumeCode(_ context.Context, codeHash string) (domain.OIDCAuthorizationCode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	code, ok := m.codes[codeHash]
+	if !ok || time.Now().After(code.ExpiresAt) {
+		return domain.OIDCAuthorizationCode{}, domain.ErrNotFound
+	}
+	delete(m.codes, codeHash)
+	return code, nil
+}
+
+// oidcTestEnv is a router backed by in-memory repositories together with an
+// admin and an end user able to sign in.
+type oidcTestEnv struct {
+	t       *testing.T
+	handler http.Handler
+	users   *memoryUsers
+	tokens  *auth.TokenIssuer
+
+	adminToken string
+	userToken  string
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	var cfg config.Config
+	cfg.Auth.TokenSecret = strings.Repeat("s", config.MinTokenSecretLength)
+	cfg.Auth.Issuer = "user-service"
+	cfg.Auth.AccessTokenTTL = 15 * time.Minute
+	cfg.OIDC.IssuerURL = testIssuer
+	cfg.OIDC.CodeTTL = time.Minute
+	cfg.OIDC.IDTokenTTL = time.Hour
+
+	keys, err := auth.LoadKeySet("")
+	if err != nil {
+		t.Fatalf("LoadKeySet: %v", err)
+	}
+
+	users := &memoryUsers{users: map[string]domain.User{
+		"admin": {ID: "admin", Name: "Admin", Email: "admin@example.com", Role: domain.RoleAdmin, Status: domain.StatusActive},
+		"alice": {ID: "alice", Name: "Alice", Email: "alice@example.com", Role: domain.RoleUser, Status: domain.StatusActive},
+	}}
+	oidc := &memoryOIDC{
+		clients: map[string]domain.OIDCClient{},
+		codes:   map[string]domain.OIDCAuthorizationCode{},
+	}
+
+	uc := usecase.New(users, nil, nil, &cfg, usecase.WithOIDC(oidc, keys))
+	env := &oidcTestEnv{
+		t:       t,
+		handler: NewRouter(cfg, NewUserHandler(uc)),
+		users:   users,
+		tokens:  auth.NewTokenIssuer(cfg.Auth.TokenSecret, cfg.Auth.Issuer),
+	}
+	env.adminToken = env.accessToken("admin", domain.RoleAdmin)
+	env.userToken = env.accessToken("alice", domain.RoleUser)
+
+	return env
+}
+
+func (e *oidcTestEnv) accessToken(userID, role string) string {
+	e.t.Helper()
+
+	token, _, err := e.tokens.Issue(userID, role, domain.TokenPurposeAccess, time.Minute)
+	if err != nil {
+		e.t.Fatalf("Issue: %v", err)
+	}
+	return token
+}
+
+func (e *oidcTestEnv) do(req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	e.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func (e *oidcTestEnv) setStatus(userID, status string) {
+	e.users.mu.Lock()
+	defer e.users.mu.Unlock()
+
+	user := e.users.users[userID]
+	user.Status = status
+	e.users.users[userID] = user
+}
+
+func (e *oidcTestEnv) registerClient() domain.RegisteredOIDCClient {
+	e.t.Helper()
+
+	body := `{"name":"test client","redirect_uris":["` + testRedirectURI + `"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/oidc/clients", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.adminToken)
+
+	rec := e.do(req)
+	if rec.Code != http.StatusCreated {
+		e.t.Fatalf("register client: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var client domain.RegisteredOIDCClient
+	if err := json.Unmarshal(rec.Body.Bytes(), &client); err != nil {
+		e.t.Fatalf("decode client: %v", err)
+	}
+	return client
+}
+
+// authorizeAs sends an authorization request with the given access token.
+func (e *oidcTestEnv) authorizeAs(token, clientID, challenge string) *httptest.ResponseRecorder {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {testRedirectURI},
+		"scope":                 {"openid profile email"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6_WzA2Mj"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/oauth2/authorize?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return e.do(req)
+}
+
+// authorize runs the authorization request as the signed in user and returns
+// the code from the redirect.
+func (e *oidcTestEnv) authorize(clientID, challenge string) string {
+	e.t.Helper()
+
+	rec := e.authorizeAs(e.userToken, clientID, challenge)
+	if rec.Code != http.StatusFound {
+		e.t.Fatalf("authorize: status %d: %s", rec.Code, rec.Body)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		e.t.Fatalf("parse redirect: %v", err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != testRedirectURI {
+		e.t.Fatalf("redirected to %q, want %q", got, testRedirectURI)
+	}
+	if got := location.Query().Get("state"); got != "xyz" {
+		e.t.Fatalf("state = %q, want %q", got, "xyz")
+	}
+
+	code := location.Query().Get("code")
+	if code == "" {
+		e.t.Fatalf("redirect has no code: %s", location)
+	}
+	return code
+}
+
+func (e *oidcTestEnv) exchange(client domain.RegisteredOIDCClient, code, verifier string) *httptest.ResponseRecorder {
+	return e.exchangeTo(client, code, verifier, testRedirectURI)
+}
+
+func (e *oidcTestEnv) exchangeTo(client domain.RegisteredOIDCClient, code, verifier, redirectURI string) *httptest.ResponseRecorder {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(client.ID, client.ClientSecret)
+
+	return e.do(req)
+}
+
+func (e *oidcTestEnv) jwksKey() *rsa.PublicKey {
+	e.t.Helper()
+
+	rec := e.do(httptest.NewRequest(http.MethodGet, "/oauth2/jwks", nil))
+	if rec.Code != http.StatusOK {
+		e.t.Fatalf("jwks: status %d", rec.Code)
+	}
+
+	var jwks domain.JWKS
+	if err := json.Unmarshal(rec.Body.Bytes(), &jwks); err != nil || len(jwks.Keys) != 1 {
+		e.t.Fatalf("decode jwks: %v (%d keys)", err, len(jwks.Keys))
+	}
+
+	n, errN := base64.RawURLEncoding.DecodeString(jwks.Keys[0].N)
+	exp, errE := base64.RawURLEncoding.DecodeString(jwks.Keys[0].E)
+	if errN != nil || errE != nil {
+		e.t.Fatalf("decode jwk: %v, %v", errN, errE)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(exp).Int64())}
+}
+
+func pkcePair(verifier string) (string, string) {
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func oidcErrorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var oidcErr domain.OIDCError
+	if err := json.Unmarshal(rec.Body.Bytes(), &oidcErr); err != nil {
+		t.Fatalf("decode error: %v: %s", err, rec.Body)
+	}
+	return oidcErr.Code
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	client := env.registerClient()
+	verifier, challenge := pkcePair(strings.Repeat("v", 64))
+
+	code := env.authorize(client.ID, challenge)
+
+	rec := env.exchange(client, code, verifier)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("token: status %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	var tokens domain.OIDCTokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("decode tokens: %v", err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.AccessToken == "" || tokens.IDToken == "" {
+		t.Fatalf("unexpected token response: %+v", tokens)
+	}
+
+	key := env.jwksKey()
+	var claims auth.OIDCClaims
+	_, err := jwt.ParseWithClaims(tokens.IDToken, &claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(testIssuer), jwt.WithAudience(client.ID))
+	if err != nil {
+		t.Fatalf("verify id token: %v", err)
+	}
+	if claims.Subject != "alice" || claims.Nonce != "n-0S6_WzA2Mj" || claims.Email != "alice@example.com" || claims.Name != "Alice" {
+		t.Errorf("unexpected id token claims: %+v", claims)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	rec = env.do(req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("userinfo: status %d: %s", rec.Code, rec.Body)
+	}
+
+	var info domain.OIDCUserInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decode userinfo: %v", err)
+	}
+	if info.Subject != "alice" || info.Email != "alice@example.com" {
+		t.Errorf("unexpected userinfo: %+v", info)
+	}
+
+	rec = env.exchange(client, code, verifier)
+	if rec.Code != http.StatusBadRequest || oidcErrorCode(t, rec) != "invalid_grant" {
+		t.Errorf("reused code: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestOIDCTokenExchangeRejected(t *testing.T) {
+	verifier, challenge := pkcePair(strings.Repeat("v", 64))
+
+	tests := []struct {
+		name       string
+		setup      func(env *oidcTestEnv, client *domain.RegisteredOIDCClient)
+		verifier   string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "wrong verifier",
+			verifier:   strings.Repeat("w", 64),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_grant",
+		},
+		{
+			name:       "missing verifier",
+			verifier:   "",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_grant",
+		},
+		{
+			name: "wrong client secret",
+			setup: func(_ *oidcTestEnv, client *domain.RegisteredOIDCClient) {
+				client.ClientSecret = "wrong"
+			},
+			verifier:   verifier,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   "invalid_client",
+		},
+		{
+			name: "suspended user",
+			setup: func(env *oidcTestEnv, _ *domain.RegisteredOIDCClient) {
+				env.setStatus("alice", domain.StatusSuspended)
+			},
+			verifier:   verifier,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_grant",
+		},
+		{
+			name: "disabled user",
+			setup: func(env *oidcTestEnv, _ *domain.RegisteredOIDCClient) {
+				env.setStatus("alice", domain.StatusDisabled)
+			},
+			verifier:   verifier,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   "invalid_grant",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			client := env.registerClient()
+			code := env.authorize(client.ID, challenge)
+
+			if tt.setup != nil {
+				tt.setup(env, &client)
+			}
+
+			rec := env.exchange(client, code, tt.verifier)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := oidcErrorCode(t, rec); got != tt.wantCode {
+				t.Errorf("error = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+// TestOIDCRejectedExchangeKeepsCode checks that a request failing client
+// authentication or naming an unregistered redirect uri does not consume the
+// code, so the legitimate client can still redeem it.
+func TestOIDCRejectedExchangeKeepsCode(t *testing.T) {
+	verifier, challenge := pkcePair(strings.Repeat("v", 64))
+
+	tests := []struct {
+		name        string
+		secret      string
+		redirectURI string
+		wantStatus  int
+		wantCode    string
+	}{
+		{"wrong client secret", "wrong", testRedirectURI, http.StatusUnauthorized, "invalid_client"},
+		{"unregistered redirect uri", "", "https://evil.example.com/callback", http.StatusBadRequest, "invalid_grant"},
+		{"redirect uri prefix", "", testRedirectURI + "/extra", http.StatusBadRequest, "invalid_grant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			client := env.registerClient()
+			code := env.authorize(client.ID, challenge)
+
+			bad := client
+			if tt.secret != "" {
+				bad.ClientSecret = tt.secret
+			}
+			rec := env.exchangeTo(bad, code, verifier, tt.redirectURI)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if got := oidcErrorCode(t, rec); got != tt.wantCode {
+				t.Errorf("error = %q, want %q", got, tt.wantCode)
+			}
+
+			if rec := env.exchange(client, code, verifier); rec.Code != http.StatusOK {
+				t.Errorf("code was consumed by the rejected request: status %d: %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+// TestOIDCAuthorizeRejectsImpersonation checks that an admin impersonating a
+// user cannot obtain OIDC tokens for that user.
+func TestOIDCAuthorizeRejectsImpersonation(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	client := env.registerClient()
+	_, challenge := pkcePair(strings.Repeat("v", 64))
+
+	token, _, err := env.tokens.IssueImpersonation("alice", domain.RoleUser, "admin", "session", time.Minute)
+	if err != nil {
+		t.Fatalf("IssueImpersonation: %v", err)
+	}
+
+	rec := env.authorizeAs(token, client.ID, challenge)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("authorize: status %d: %s", rec.Code, rec.Body)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if got := location.Query().Get("error"); got != "access_denied" {
+		t.Errorf("error = %q, want access_denied", got)
+	}
+	if code := location.Query().Get("code"); code != "" {
+		t.Errorf("impersonation session got a code: %s", location)
+	}
+}
@@ -0,0 +1,23 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/openapi"
+)
+
+func (h *UserHandler) OpenAPIDocument(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(openapi.Document()); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write openapi document", "error", err)
+	}
+}
+
+func (h *UserHandler) APIExplorer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err := w.Write(openapi.Explorer()); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write api explorer", "error", err)
+	}
+}
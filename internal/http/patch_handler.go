@@ -0,0 +1,52 @@
+package http
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// acceptPatch advertises the supported patch formats (RFC 5789 section 3.1).
+var acceptPatch = mergePatchContentType + ", " + jsonPatchContentType
+
+func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	w.Header().Set("Accept-Patch", acceptPatch)
+	if !authorizeSelfOrAdmin(w, r, id) {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var format string
+	switch mediaType {
+	case mergePatchContentType:
+		format = domain.PatchFormatMerge
+	case jsonPatchContentType:
+		format = domain.PatchFormatJSON
+	default:
+		writeError(w, r, http.StatusUnsupportedMediaType, "Content-Type must be "+mergePatchContentType+" or "+jsonPatchContentType)
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, r, http.StatusRequestEntityTooLarge, "Patch is too large")
+		return
+	}
+
+	user, err := h.uc.PatchUser(r.Context(), id, format, patch)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
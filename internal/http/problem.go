@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/highway-to-Golang/user-service/internal/correlation"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	// problemTypeBase prefixes the error code to form the problem type. The
+	// resulting URIs are stable identifiers and are not meant to be resolved.
+	problemTypeBase = "urn:user-service:problem:"
+)
+
+// Problem is an RFC 7807 problem details object extended with a
+// machine-readable code, the request and trace IDs of the request and field
+// violations.
+type Problem struct {
+	Type       string                `json:"type"`
+	Title      string                `json:"title"`
+	Status     int                   `json:"status"`
+	Detail     string                `json:"detail,omitempty"`
+	Instance   string                `json:"instance,omitempty"`
+	Code       string                `json:"code"`
+	RequestID  string                `json:"request_id"`
+	TraceID    string                `json:"trace_id"`
+	Violations []apperrors.Violation `json:"violations,omitempty"`
+}
+
+// writeProblem renders err with the code and status it maps to. Messages of
+// internal errors are logged and never sent to the client.
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	e := apperrors.From(err)
+
+	problem := newProblem(r, e.Status, e.Code, e.Message)
+	problem.Violations = e.Violations
+
+	if e.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed", "error", err, "code", e.Code,
+			"method", r.Method, "path", r.URL.Path)
+	}
+
+	writeProblemJSON(w, problem)
+}
+
+// writeError renders a problem for a failure detected by the handler itself,
+// e.g. a malformed body, using the generic code of the status.
+func writeError(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblemJSON(w, newProblem(r, status, apperrors.CodeForStatus(status), detail))
+}
+
+func newProblem(r *http.Request, status int, code, detail string) Problem {
+	ids := requestIDs(r)
+	return Problem{
+		Type:      problemTypeBase + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: ids.RequestID,
+		TraceID:   ids.TraceID,
+	}
+}
+
+func writeProblemJSON(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		slog.Error("failed to write problem", "error", err)
+	}
+}
+
+// requestIDs returns the IDs stored by RequestIDMiddleware, or takes them
+// from the headers of requests that did not pass through it.
+func requestIDs(r *http.Request) correlation.IDs {
+	if ids, ok := correlation.IDsFromContext(r.Context()); ok {
+		return ids
+	}
+	return correlation.FromHeaders(r.Header)
+}
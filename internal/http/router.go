@@ -2,16 +2,129 @@ package http
 
 import (
 	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/graphql"
+	"github.com/highway-to-Golang/user-service/internal/metrics"
 )
 
-func NewRouter(userHandler *UserHandler) http.Handler {
-	mux := http.NewServeMux()
+func NewRouter(cfg config.Config, userHandler *UserHandler) http.Handler {
+	mux := newRoutes(cfg, userHandler).ServeMux
+
+	rateLimited := RateLimitMiddleware(userHandler.uc, cfg.RateLimit, mux)(mux)
+	return SpanRouteMiddleware(mux)(MetricsMiddleware(mux)(rateLimited))
+}
+
+// routes is a ServeMux that remembers the patterns registered on it, so that
+// they can be checked against the OpenAPI document.
+type routes struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (r *routes) Handle(pattern string, handler http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+	r.ServeMux.Handle(pattern, handler)
+}
+
+func (r *routes) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	r.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// newRoutes registers the handlers of the service without the middleware
+// that NewRouter wraps around all of them.
+func newRoutes(cfg config.Config, userHandler *UserHandler) *routes {
+	mux := &routes{ServeMux: http.NewServeMux()}
+
+	authenticated := AuthMiddleware(userHandler.uc, domain.TokenPurposeAccess)
+	enrolling := AuthMiddleware(userHandler.uc, domain.TokenPurposeAccess, domain.TokenPurposeMFAEnroll)
+	adminOnly := func(h http.HandlerFunc) http.Handler {
+		return authenticated(RequireRole(domain.RoleAdmin)(h))
+	}
+	scimAuth := SCIMAuthMiddleware(userHandler.uc, cfg.SCIM.Token)
+	scimRoute := func(h http.HandlerFunc) http.Handler {
+		return scimAuth(h)
+	}
+
+	mux.Handle("GET /api/users", adminOnly(userHandler.GetAllUsers))
+	mux.Handle("POST /api/users", adminOnly(userHandler.CreateUser))
+	mux.Handle("GET /api/users/{id}", authenticated(http.HandlerFunc(userHandler.GetUser)))
+	mux.Handle("PUT /api/users/{id}", authenticated(http.HandlerFunc(userHandler.UpdateUser)))
+	mux.Handle("PATCH /api/users/{id}", authenticated(http.HandlerFunc(userHandler.PatchUser)))
+	mux.Handle("DELETE /api/users/{id}", adminOnly(userHandler.DeleteUser))
+	mux.Handle("POST /api/users/{target}", adminOnly(userHandler.UserAction))
+	mux.Handle("DELETE /api/users/{id}/mfa", adminOnly(userHandler.ResetMFA))
+	mux.Handle("POST /api/users/{id}/unlock", adminOnly(userHandler.UnlockUser))
+	mux.Handle("POST /api/users/{id}/email/verification", authenticated(http.HandlerFunc(userHandler.RequestEmailVerification)))
+	mux.Handle("GET /api/users/{id}/emails", authenticated(http.HandlerFunc(userHandler.ListUserEmails)))
+	mux.Handle("POST /api/users/{id}/emails", authenticated(http.HandlerFunc(userHandler.AddUserEmail)))
+	mux.Handle("DELETE /api/users/{id}/emails/{email}", authenticated(http.HandlerFunc(userHandler.RemoveUserEmail)))
+	mux.Handle("POST /api/users/{id}/emails/{email}/primary", authenticated(http.HandlerFunc(userHandler.PromoteUserEmail)))
+	mux.HandleFunc("POST /api/email/verify", userHandler.VerifyEmail)
+	mux.Handle("PUT /api/users/{id}/avatar", authenticated(http.HandlerFunc(userHandler.UploadAvatar)))
+	mux.Handle("DELETE /api/users/{id}/avatar", authenticated(http.HandlerFunc(userHandler.DeleteAvatar)))
+	mux.HandleFunc("GET /avatars/{id}/{version}/{name}", userHandler.ServeAvatar)
+
+	mux.HandleFunc("GET /api/usernames/{name}", userHandler.GetUserByUsername)
+	mux.HandleFunc("GET /api/usernames/{name}/availability", userHandler.UsernameAvailability)
+
+	mux.Handle("GET /api/impersonations", adminOnly(userHandler.ListImpersonationSessions))
+	mux.Handle("GET /api/inactivity/report", adminOnly(userHandler.InactivityReport))
+
+	mux.Handle("POST /api/invitations", adminOnly(userHandler.CreateInvitation))
+	mux.Handle("GET /api/invitations", adminOnly(userHandler.ListInvitations))
+	mux.Handle("GET /api/invitations/{id}", adminOnly(userHandler.GetInvitation))
+	mux.Handle("POST /api/invitations/{id}/resend", adminOnly(userHandler.ResendInvitation))
+	mux.Handle("POST /api/invitations/{id}/revoke", adminOnly(userHandler.RevokeInvitation))
+	mux.HandleFunc("POST /api/invitations/accept", userHandler.AcceptInvitation)
+
+	mux.HandleFunc("POST /api/auth/login", userHandler.Login)
+	mux.HandleFunc("POST /api/auth/login/mfa", userHandler.LoginMFA)
+	mux.Handle("POST /api/auth/mfa/enroll", enrolling(http.HandlerFunc(userHandler.EnrollMFA)))
+	mux.Handle("POST /api/auth/mfa/confirm", enrolling(http.HandlerFunc(userHandler.ConfirmMFA)))
+	mux.Handle("POST /api/auth/mfa/disable", authenticated(http.HandlerFunc(userHandler.DisableMFA)))
+
+	mux.Handle("POST /api/oidc/clients", adminOnly(userHandler.RegisterOIDCClient))
+	mux.Handle("GET /api/oidc/clients", adminOnly(userHandler.ListOIDCClients))
+	mux.Handle("DELETE /api/oidc/clients/{id}", adminOnly(userHandler.DeleteOIDCClient))
+
+	mux.HandleFunc("GET /.well-known/openid-configuration", userHandler.OIDCDiscovery)
+	mux.HandleFunc("GET /oauth2/jwks", userHandler.OIDCJWKS)
+	mux.Handle("GET /oauth2/authorize", authenticated(http.HandlerFunc(userHandler.OIDCAuthorize)))
+	mux.HandleFunc("POST /oauth2/token", userHandler.OIDCToken)
+	mux.HandleFunc("GET /userinfo", userHandler.OIDCUserInfo)
+	mux.HandleFunc("POST /userinfo", userHandler.OIDCUserInfo)
+
+	mux.Handle("GET /scim/v2/Users", scimRoute(userHandler.SCIMListUsers))
+	mux.Handle("POST /scim/v2/Users", scimRoute(userHandler.SCIMCreateUser))
+	mux.Handle("GET /scim/v2/Users/{id}", scimRoute(userHandler.SCIMGetUser))
+	mux.Handle("PUT /scim/v2/Users/{id}", scimRoute(userHandler.SCIMReplaceUser))
+	mux.Handle("PATCH /scim/v2/Users/{id}", scimRoute(userHandler.SCIMPatchUser))
+	mux.Handle("DELETE /scim/v2/Users/{id}", scimRoute(userHandler.SCIMDeleteUser))
+	mux.Handle("GET /scim/v2/Groups", scimRoute(userHandler.SCIMListGroups))
+	mux.Handle("POST /scim/v2/Groups", scimRoute(userHandler.SCIMCreateGroup))
+	mux.Handle("GET /scim/v2/Groups/{id}", scimRoute(userHandler.SCIMGetGroup))
+	mux.Handle("PUT /scim/v2/Groups/{id}", scimRoute(userHandler.SCIMReplaceGroup))
+	mux.Handle("PATCH /scim/v2/Groups/{id}", scimRoute(userHandler.SCIMPatchGroup))
+	mux.Handle("DELETE /scim/v2/Groups/{id}", scimRoute(userHandler.SCIMDeleteGroup))
+	mux.Handle("GET /scim/v2/ServiceProviderConfig", scimRoute(userHandler.SCIMServiceProviderConfig))
+	mux.Handle("GET /scim/v2/Schemas", scimRoute(userHandler.SCIMSchemas))
+	mux.Handle("GET /scim/v2/Schemas/{id}", scimRoute(userHandler.SCIMGetSchema))
+	mux.Handle("GET /scim/v2/ResourceTypes", scimRoute(userHandler.SCIMResourceTypes))
+
+	graphqlHandler := OperationAuthMiddleware(userHandler.uc, domain.TokenPurposeAccess)(graphql.NewHandler(userHandler.uc, cfg.GraphQL))
+	mux.Handle("GET /graphql", graphqlHandler)
+	mux.Handle("POST /graphql", graphqlHandler)
+
+	mux.HandleFunc("GET /openapi.json", userHandler.OpenAPIDocument)
+	mux.HandleFunc("GET /docs", userHandler.APIExplorer)
 
-	mux.HandleFunc("GET /api/users", userHandler.GetAllUsers)
-	mux.HandleFunc("POST /api/users", userHandler.CreateUser)
-	mux.HandleFunc("GET /api/users/{id}", userHandler.GetUser)
-	mux.HandleFunc("PUT /api/users/{id}", userHandler.UpdateUser)
-	mux.HandleFunc("DELETE /api/users/{id}", userHandler.DeleteUser)
+	mux.HandleFunc("GET /healthz", userHandler.Liveness)
+	mux.HandleFunc("GET /readyz", userHandler.Readiness)
+	mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
 
 	return mux
 }
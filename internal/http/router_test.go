@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/openapi"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// userActions are the custom methods dispatched by
+// POST /api/users/{target}.
+var userActions = []string{"activate", "suspend", "disable", "delete", "restore", "impersonate"}
+
+// registeredRoutes builds the routes of NewRouter.
+func registeredRoutes(t *testing.T) *routes {
+	t.Helper()
+
+	var cfg config.Config
+	return newRoutes(cfg, NewUserHandler(usecase.New(nil, nil, nil, &cfg)))
+}
+
+var wildcard = regexp.MustCompile(`\{[^}]+\}`)
+
+func TestRoutesAreDocumented(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patterns := registeredRoutes(t).patterns
+	if len(patterns) == 0 {
+		t.Fatal("no routes registered")
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			method, path, ok := strings.Cut(pattern, " ")
+			if !ok {
+				t.Fatalf("pattern %q has no method", pattern)
+			}
+
+			paths := []string{wildcard.ReplaceAllString(path, "x")}
+			if strings.HasSuffix(path, "/{target}") {
+				paths = paths[:0]
+				for _, action := range userActions {
+					paths = append(paths, strings.TrimSuffix(path, "{target}")+"x:"+action)
+				}
+			}
+
+			for _, p := range paths {
+				if _, err := spec.Find(method, p); err != nil {
+					t.Errorf("%s %s is not documented: %v", method, p, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentedRoutesAreRegistered(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := registeredRoutes(t).ServeMux
+	for template, item := range spec.Paths {
+		operations := map[string]*openapi.Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPut:    item.Put,
+			http.MethodPost:   item.Post,
+			http.MethodDelete: item.Delete,
+			http.MethodPatch:  item.Patch,
+		}
+		for method, op := range operations {
+			if op == nil {
+				continue
+			}
+			req := httptest.NewRequest(method, wildcard.ReplaceAllString(template, "x"), nil)
+			if _, pattern := mux.Handler(req); pattern == "" {
+				t.Errorf("%s %s (%s) is documented but not routed", method, template, op.OperationID)
+			}
+		}
+	}
+}
+
+func TestUserActionsAreKnown(t *testing.T) {
+	for _, action := range userActions {
+		if action != "impersonate" && !usecase.IsStatusAction(action) {
+			t.Errorf("%q is not a status action", action)
+		}
+	}
+}
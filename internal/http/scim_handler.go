@@ -0,0 +1,635 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/scim"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// SCIMAuthMiddleware accepts the static SCIM token from the configuration or
+// an access token of an admin.
+func SCIMAuthMiddleware(uc *usecase.UseCase, staticToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				writeSCIMError(w, r, scim.NewError(http.StatusUnauthorized, "", "missing bearer token"))
+				return
+			}
+
+			if staticToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(staticToken)) == 1 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			principal, err := uc.Authenticate(r.Context(), token, domain.TokenPurposeAccess)
+			if err != nil {
+				if errors.Is(err, domain.ErrUnauthorized) {
+					writeSCIMError(w, r, scim.NewError(http.StatusUnauthorized, "", "invalid token"))
+					return
+				}
+				writeSCIMError(w, r, err)
+				return
+			}
+
+			if principal.Role != domain.RoleAdmin {
+				writeSCIMError(w, r, scim.NewError(http.StatusForbidden, "", "forbidden"))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func writeSCIM(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", scim.ContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error("failed to encode scim response", "error", err)
+	}
+}
+
+func writeSCIMError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.Is(err, domain.ErrNotFound):
+		scimErr = scim.NewError(http.StatusNotFound, "", "resource not found")
+	case errors.Is(err, domain.ErrConflict):
+		scimErr = scim.NewError(http.StatusConflict, scim.ScimTypeUniqueness, "resource already exists")
+	case errors.Is(err, domain.ErrInvalidTransition):
+		scimErr = scim.NewError(http.StatusConflict, scim.ScimTypeMutability, "%s", err.Error())
+	case errors.Is(err, domain.ErrInvalidInput):
+		scimErr = scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidValue, "%s", err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "scim request failed", "error", err)
+		scimErr = scim.NewError(http.StatusInternalServerError, "", "internal error")
+	}
+
+	writeSCIM(w, scimErr.StatusCode(), scimErr)
+}
+
+// decodeSCIM decodes a SCIM request body of at most maxBodyBytes into dst and
+// returns a *scim.Error when it cannot.
+func decodeSCIM(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes)).Decode(dst)
+	if err == nil {
+		return nil
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return scim.NewError(http.StatusRequestEntityTooLarge, "", "request body must not exceed %d bytes", maxBodyBytes)
+	}
+	return scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidSyntax, "invalid request body")
+}
+
+func scimBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/scim/v2"
+}
+
+// scimPreconditionFailed reports whether an If-Match header is present and
+// does not match the current version of the resource.
+func scimPreconditionFailed(r *http.Request, etag string) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return false
+		}
+	}
+	return true
+}
+
+func scimNotModified(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if c := strings.TrimSpace(candidate); c == etag || c == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func scimPagination(r *http.Request) (int, int, error) {
+	query := r.URL.Query()
+
+	startIndex := 1
+	if v := query.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidValue, "invalid startIndex")
+		}
+		startIndex = n
+	}
+
+	count := scim.DefaultPageSize
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, 0, scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidValue, "invalid count")
+		}
+		count = min(n, scim.MaxPageSize)
+	}
+
+	return startIndex, count, nil
+}
+
+func scimFilter(resources []interface{}, rawFilter string) ([]interface{}, error) {
+	if rawFilter == "" {
+		return resources, nil
+	}
+
+	filter, err := scim.ParseFilter(rawFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []interface{}
+	for _, resource := range resources {
+		m, err := scim.ToMap(resource)
+		if err != nil {
+			return nil, err
+		}
+		if filter.Match(m) {
+			matched = append(matched, resource)
+		}
+	}
+
+	return matched, nil
+}
+
+func (h *UserHandler) scimUser(r *http.Request, user domain.User, groups []domain.Group) scim.User {
+	resource := scim.UserFromDomain(user, scimBaseURL(r))
+	for _, g := range groups {
+		resource.Groups = append(resource.Groups, scim.MultiValued{
+			Value:   g.ID,
+			Display: g.Name,
+			Ref:     scimBaseURL(r) + "/Groups/" + g.ID,
+		})
+	}
+	return resource
+}
+
+func (h *UserHandler) writeSCIMUser(w http.ResponseWriter, r *http.Request, status int, user domain.User) {
+	groups, err := h.uc.GetUserGroups(r.Context(), []string{user.ID})
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	resource := h.scimUser(r, user, groups[user.ID])
+	w.Header().Set("ETag", resource.Meta.Version)
+	if status == http.StatusCreated {
+		w.Header().Set("Location", resource.Meta.Location)
+	}
+	writeSCIM(w, status, resource)
+}
+
+func (h *UserHandler) SCIMListUsers(w http.ResponseWriter, r *http.Request) {
+	startIndex, count, err := scimPagination(r)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	users, err := h.uc.GetAllUsers(r.Context(), domain.UserFilter{})
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+
+	groups, err := h.uc.GetUserGroups(r.Context(), ids)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	resources := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, h.scimUser(r, u, groups[u.ID]))
+	}
+
+	resources, err = scimFilter(resources, r.URL.Query().Get("filter"))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, scim.Paginate(resources, startIndex, count))
+}
+
+func (h *UserHandler) SCIMGetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.uc.GetUser(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if scimNotModified(r, scim.ETag(user.UpdatedAt)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.writeSCIMUser(w, r, http.StatusOK, user)
+}
+
+func (h *UserHandler) SCIMCreateUser(w http.ResponseWriter, r *http.Request) {
+	var resource scim.User
+	if err := decodeSCIM(w, r, &resource); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	req, err := resource.CreateRequest()
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	user, err := h.uc.CreateUser(r.Context(), "", req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	h.writeSCIMUser(w, r, http.StatusCreated, user)
+}
+
+func (h *UserHandler) SCIMReplaceUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	current, err := h.uc.GetUser(r.Context(), id)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if scimPreconditionFailed(r, scim.ETag(current.UpdatedAt)) {
+		writeSCIMError(w, r, scim.NewError(http.StatusPreconditionFailed, "", "resource version mismatch"))
+		return
+	}
+
+	var resource scim.User
+	if err := decodeSCIM(w, r, &resource); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	req, err := resource.UpdateRequest()
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	user, err := h.uc.UpdateUser(r.Context(), id, req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if resource.Active != nil {
+		user, err = h.uc.SetUserActive(r.Context(), id, *resource.Active, "SCIM provisioning")
+		if err != nil {
+			writeSCIMError(w, r, err)
+			return
+		}
+	}
+
+	h.writeSCIMUser(w, r, http.StatusOK, user)
+}
+
+func (h *UserHandler) SCIMPatchUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	current, err := h.uc.GetUser(r.Context(), id)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if scimPreconditionFailed(r, scim.ETag(current.UpdatedAt)) {
+		writeSCIMError(w, r, scim.NewError(http.StatusPreconditionFailed, "", "resource version mismatch"))
+		return
+	}
+
+	var patch scim.PatchRequest
+	if err := decodeSCIM(w, r, &patch); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+	if !slices.Contains(patch.Schemas, scim.SchemaPatchOp) {
+		writeSCIMError(w, r, scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidSyntax, "invalid patch request"))
+		return
+	}
+
+	m, err := scim.ToMap(scim.UserFromDomain(current, scimBaseURL(r)))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if err := scim.ApplyPatch(m, patch.Operations); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	var resource scim.User
+	if err := scim.FromMap(m, &resource); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	req, err := resource.UpdateRequest()
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	user, err := h.uc.UpdateUser(r.Context(), id, req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if resource.Active != nil {
+		user, err = h.uc.SetUserActive(r.Context(), id, *resource.Active, "SCIM provisioning")
+		if err != nil {
+			writeSCIMError(w, r, err)
+			return
+		}
+	}
+
+	h.writeSCIMUser(w, r, http.StatusOK, user)
+}
+
+func (h *UserHandler) SCIMDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if r.Header.Get("If-Match") != "" {
+		current, err := h.uc.GetUser(r.Context(), id)
+		if err != nil {
+			writeSCIMError(w, r, err)
+			return
+		}
+		if scimPreconditionFailed(r, scim.ETag(current.UpdatedAt)) {
+			writeSCIMError(w, r, scim.NewError(http.StatusPreconditionFailed, "", "resource version mismatch"))
+			return
+		}
+	}
+
+	if err := h.uc.DeleteUser(r.Context(), id); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) writeSCIMGroup(w http.ResponseWriter, r *http.Request, status int, group domain.Group) {
+	resource := scim.GroupFromDomain(group, scimBaseURL(r))
+	w.Header().Set("ETag", resource.Meta.Version)
+	if status == http.StatusCreated {
+		w.Header().Set("Location", resource.Meta.Location)
+	}
+	writeSCIM(w, status, resource)
+}
+
+func (h *UserHandler) SCIMListGroups(w http.ResponseWriter, r *http.Request) {
+	startIndex, count, err := scimPagination(r)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	groups, err := h.uc.GetAllGroups(r.Context())
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	resources := make([]interface{}, 0, len(groups))
+	for _, g := range groups {
+		resources = append(resources, scim.GroupFromDomain(g, scimBaseURL(r)))
+	}
+
+	resources, err = scimFilter(resources, r.URL.Query().Get("filter"))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, scim.Paginate(resources, startIndex, count))
+}
+
+func (h *UserHandler) SCIMGetGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := h.uc.GetGroup(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if scimNotModified(r, scim.ETag(group.UpdatedAt)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.writeSCIMGroup(w, r, http.StatusOK, group)
+}
+
+func (h *UserHandler) SCIMCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var resource scim.Group
+	if err := decodeSCIM(w, r, &resource); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	req, err := resource.CreateRequest()
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	group, err := h.uc.CreateGroup(r.Context(), req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	h.writeSCIMGroup(w, r, http.StatusCreated, group)
+}
+
+func (h *UserHandler) SCIMReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	current, err := h.uc.GetGroup(r.Context(), id)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if scimPreconditionFailed(r, scim.ETag(current.UpdatedAt)) {
+		writeSCIMError(w, r, scim.NewError(http.StatusPreconditionFailed, "", "resource version mismatch"))
+		return
+	}
+
+	var resource scim.Group
+	if err := decodeSCIM(w, r, &resource); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	req, err := resource.UpdateRequest()
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	group, err := h.uc.UpdateGroup(r.Context(), id, req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	h.writeSCIMGroup(w, r, http.StatusOK, group)
+}
+
+func (h *UserHandler) SCIMPatchGroup(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	current, err := h.uc.GetGroup(r.Context(), id)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if scimPreconditionFailed(r, scim.ETag(current.UpdatedAt)) {
+		writeSCIMError(w, r, scim.NewError(http.StatusPreconditionFailed, "", "resource version mismatch"))
+		return
+	}
+
+	var patch scim.PatchRequest
+	if err := decodeSCIM(w, r, &patch); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+	if !slices.Contains(patch.Schemas, scim.SchemaPatchOp) {
+		writeSCIMError(w, r, scim.NewError(http.StatusBadRequest, scim.ScimTypeInvalidSyntax, "invalid patch request"))
+		return
+	}
+
+	m, err := scim.ToMap(scim.GroupFromDomain(current, scimBaseURL(r)))
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	if err := scim.ApplyPatch(m, patch.Operations); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	var resource scim.Group
+	if err := scim.FromMap(m, &resource); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	req, err := resource.UpdateRequest()
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	group, err := h.uc.UpdateGroup(r.Context(), id, req)
+	if err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	h.writeSCIMGroup(w, r, http.StatusOK, group)
+}
+
+func (h *UserHandler) SCIMDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if r.Header.Get("If-Match") != "" {
+		current, err := h.uc.GetGroup(r.Context(), id)
+		if err != nil {
+			writeSCIMError(w, r, err)
+			return
+		}
+		if scimPreconditionFailed(r, scim.ETag(current.UpdatedAt)) {
+			writeSCIMError(w, r, scim.NewError(http.StatusPreconditionFailed, "", "resource version mismatch"))
+			return
+		}
+	}
+
+	if err := h.uc.DeleteGroup(r.Context(), id); err != nil {
+		writeSCIMError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) SCIMServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeSCIM(w, http.StatusOK, scim.NewServiceProviderConfig(scimBaseURL(r)))
+}
+
+func (h *UserHandler) SCIMSchemas(w http.ResponseWriter, r *http.Request) {
+	schemas := scim.Schemas(scimBaseURL(r))
+
+	resources := make([]interface{}, 0, len(schemas))
+	for _, s := range schemas {
+		resources = append(resources, s)
+	}
+
+	writeSCIM(w, http.StatusOK, scim.Paginate(resources, 1, len(resources)))
+}
+
+func (h *UserHandler) SCIMGetSchema(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	for _, s := range scim.Schemas(scimBaseURL(r)) {
+		if s.ID == id {
+			writeSCIM(w, http.StatusOK, s)
+			return
+		}
+	}
+
+	writeSCIMError(w, r, scim.NotFound("Schema", id))
+}
+
+func (h *UserHandler) SCIMResourceTypes(w http.ResponseWriter, r *http.Request) {
+	types := scim.ResourceTypes(scimBaseURL(r))
+
+	resources := make([]interface{}, 0, len(types))
+	for _, t := range types {
+		resources = append(resources, t)
+	}
+
+	writeSCIM(w, http.StatusOK, scim.Paginate(resources, 1, len(resources)))
+}
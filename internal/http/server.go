@@ -4,20 +4,33 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/openapi"
 )
 
 type Server struct {
 	httpServer *http.Server
+	listener   net.Listener
+	// name tells the API and admin servers apart in logs.
+	name string
 }
 
 func NewServer(cfg config.Config, userHandler *UserHandler) *Server {
-	router := NewRouter(userHandler)
+	var handler http.Handler = NewRouter(cfg, userHandler)
+	if cfg.OpenAPI.Validate && cfg.App.Env != "production" {
+		slog.Info("validating requests and responses against the openapi document", "env", cfg.App.Env)
+		// Avatar uploads are the only bodies allowed to exceed maxBodyBytes.
+		maxBytes := max(maxBodyBytes, userHandler.uc.AvatarUploadLimit()+multipartOverhead)
+		handler = OpenAPIValidationMiddleware(openapi.MustLoad(), maxBytes)(handler)
+	}
 
-	handler := LoggingMiddleware(router)
+	handler = LoggingMiddleware(handler)
+	handler = RequestIDMiddleware(handler)
+	handler = TracingMiddleware(handler)
 
 	addr := fmt.Sprintf("%s:%s", cfg.HTTP.Host, cfg.HTTP.Port)
 
@@ -31,13 +44,27 @@ func NewServer(cfg config.Config, userHandler *UserHandler) *Server {
 
 	return &Server{
 		httpServer: httpServer,
+		name:       "api",
+	}
+}
+
+// Listen binds the address of the server, so that a port that is in use fails
+// the startup instead of the background Serve.
+func (s *Server) Listen() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
 	}
+	s.listener = listener
+
+	return nil
 }
 
-func (s *Server) Start() error {
-	slog.Info("starting HTTP server", "address", s.httpServer.Addr)
+// Serve serves requests on the listener bound by Listen until Shutdown.
+func (s *Server) Serve() error {
+	slog.Info("starting HTTP server", "server", s.name, "address", s.listener.Addr().String())
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := s.httpServer.Serve(s.listener); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start server: %w", err)
 	}
 
@@ -45,12 +72,12 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	slog.Info("shutting down HTTP server")
+	slog.InfoContext(ctx, "shutting down HTTP server", "server", s.name)
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("failed to shutdown server: %w", err)
 	}
 
-	slog.Info("HTTP server stopped")
+	slog.InfoContext(ctx, "HTTP server stopped", "server", s.name)
 	return nil
 }
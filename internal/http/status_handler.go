@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/usecase"
+)
+
+// UserAction dispatches custom methods of the form
+// POST /api/users/{id}:{action}, e.g. /api/users/123:suspend.
+func (h *UserHandler) UserAction(w http.ResponseWriter, r *http.Request) {
+	id, action, ok := strings.Cut(r.PathValue("target"), ":")
+	if !ok || id == "" {
+		writeError(w, r, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch {
+	case usecase.IsStatusAction(action):
+		h.changeUserStatus(w, r, id, action)
+	case action == "impersonate":
+		h.impersonate(w, r, id)
+	default:
+		writeError(w, r, http.StatusNotFound, "Unknown action")
+	}
+}
+
+func (h *UserHandler) changeUserStatus(w http.ResponseWriter, r *http.Request, id, action string) {
+	var req domain.ChangeStatusRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.uc.ChangeUserStatus(r.Context(), id, action, req.Reason)
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
@@ -0,0 +1,23 @@
+package http
+
+import "net/http"
+
+func (h *UserHandler) UsernameAvailability(w http.ResponseWriter, r *http.Request) {
+	availability, err := h.uc.CheckUsernameAvailability(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, availability)
+}
+
+func (h *UserHandler) GetUserByUsername(w http.ResponseWriter, r *http.Request) {
+	user, err := h.uc.GetUserByUsername(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
@@ -0,0 +1,112 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions too large")
+)
+
+// Variant is a re-encoded, square copy of an image.
+type Variant struct {
+	Size        int
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// ContentType sniffs the content type of an image upload. The declared type
+// of an upload is not trusted.
+func ContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// Resize decodes an image and produces one square variant per size. Images are
+// re-encoded from pixels only, so EXIF and other metadata are dropped; the
+// EXIF orientation of JPEGs is applied first. PNG and GIF input is encoded as
+// PNG to keep transparency, everything else as JPEG.
+func Resize(data []byte, sizes []int, maxDimension int) ([]Variant, error) {
+	contentType := ContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if config.Width > maxDimension || config.Height > maxDimension {
+		return nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrTooLarge, config.Width, config.Height, maxDimension)
+	}
+
+	src, err := decode(contentType, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	if contentType == "image/jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+	src = cropSquare(src)
+
+	variants := make([]Variant, 0, len(sizes))
+	for _, size := range sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+		variant := Variant{Size: size}
+		var buf bytes.Buffer
+		if contentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+			variant.ContentType, variant.Extension = "image/jpeg", "jpg"
+		} else {
+			err = png.Encode(&buf, dst)
+			variant.ContentType, variant.Extension = "image/png", "png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		variant.Data = buf.Bytes()
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+func decode(contentType string, data []byte) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Decode(r)
+	case "image/png":
+		return png.Decode(r)
+	default:
+		return gif.Decode(r)
+	}
+}
+
+// cropSquare returns the centered square of an image.
+func cropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x, y), draw.Src)
+	return dst
+}
@@ -0,0 +1,123 @@
+// Package lifecycle starts and stops the components of the service. Stop
+// hooks run in the reverse order of their registration, so that components
+// are stopped before the dependencies they were started with.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Manager collects the stop hooks and background tasks of the service.
+type Manager struct {
+	timeout time.Duration
+	hooks   []hook
+
+	failOnce sync.Once
+	failed   chan struct{}
+	failure  error
+}
+
+type hook struct {
+	name    string
+	stop    func(ctx context.Context) error
+	timeout time.Duration
+}
+
+// HookOption configures a stop hook.
+type HookOption func(*hook)
+
+// WithTimeout overrides the default deadline of a stop hook.
+func WithTimeout(timeout time.Duration) HookOption {
+	return func(h *hook) {
+		h.timeout = timeout
+	}
+}
+
+// New returns a manager that gives every stop hook timeout to finish, unless
+// the hook sets its own.
+func New(timeout time.Duration) *Manager {
+	return &Manager{
+		timeout: timeout,
+		failed:  make(chan struct{}),
+	}
+}
+
+// OnStop registers stop to be run by Shutdown. Hooks run one at a time, last
+// registered first, each with its own deadline.
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error, opts ...HookOption) {
+	h := hook{name: name, stop: stop, timeout: m.timeout}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	m.hooks = append(m.hooks, h)
+}
+
+// Go runs a long running task, e.g. a server, in the background. A task that
+// fails makes Wait return its error, which shuts the service down.
+func (m *Manager) Go(name string, run func() error) {
+	go func() {
+		if err := run(); err != nil {
+			m.failOnce.Do(func() {
+				m.failure = fmt.Errorf("%s: %w", name, err)
+				close(m.failed)
+			})
+		}
+	}()
+}
+
+// Wait blocks until ctx is done, which is a regular shutdown, or a task
+// started with Go fails.
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		slog.InfoContext(ctx, "shutdown signal received")
+		return nil
+	case <-m.failed:
+		slog.ErrorContext(ctx, "shutting down after a component failed", "error", m.failure)
+		return m.failure
+	}
+}
+
+// Shutdown runs the stop hooks in reverse order. A hook that misses its
+// deadline is abandoned so that the remaining hooks still run. The errors of
+// all hooks are returned together.
+func (m *Manager) Shutdown() error {
+	var errs []error
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		if err := m.hooks[i].run(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	m.hooks = nil
+
+	return errors.Join(errs...)
+}
+
+func (h hook) run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to stop component", "component", h.name, "error", err)
+			return fmt.Errorf("failed to stop %s: %w", h.name, err)
+		}
+		slog.InfoContext(ctx, "component stopped", "component", h.name, "duration_ms", time.Since(start).Milliseconds())
+		return nil
+	case <-ctx.Done():
+		slog.ErrorContext(ctx, "component did not stop in time", "component", h.name, "timeout", h.timeout)
+		return fmt.Errorf("%s did not stop within %s", h.name, h.timeout)
+	}
+}
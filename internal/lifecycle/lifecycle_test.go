@@ -0,0 +1,167 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownRunsHooksInReverseOrder(t *testing.T) {
+	m := New(time.Second)
+
+	var stopped []string
+	for _, name := range []string{"database", "cache", "server"} {
+		m.OnStop(name, func(context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+
+	if err := m.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if want := []string{"server", "cache", "database"}; !slices.Equal(stopped, want) {
+		t.Errorf("stopped %v, want %v", stopped, want)
+	}
+
+	stopped = nil
+	if err := m.Shutdown(); err != nil || len(stopped) != 0 {
+		t.Errorf("second Shutdown ran %v, err %v, want no hooks", stopped, err)
+	}
+}
+
+func TestShutdownTimeouts(t *testing.T) {
+	errStop := errors.New("connection reset")
+
+	// blocking waits for its deadline, or much longer if it was not given
+	// one, so that a missing deadline shows up as a slow test.
+	blocking := func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Second):
+		}
+		return nil
+	}
+	// stuck ignores its deadline.
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	stuck := func(context.Context) error {
+		<-release
+		return nil
+	}
+
+	tests := []struct {
+		name        string
+		defaultWait time.Duration
+		stop        func(ctx context.Context) error
+		opts        []HookOption
+		wantErr     string
+		maxDuration time.Duration
+	}{
+		{
+			name:        "hook finishes",
+			defaultWait: time.Second,
+			stop:        func(context.Context) error { return nil },
+			maxDuration: time.Second,
+		},
+		{
+			name:        "hook fails",
+			defaultWait: time.Second,
+			stop:        func(context.Context) error { return errStop },
+			wantErr:     "failed to stop component: connection reset",
+			maxDuration: time.Second,
+		},
+		{
+			name:        "default deadline",
+			defaultWait: 20 * time.Millisecond,
+			stop:        blocking,
+			wantErr:     "component did not stop within 20ms",
+			maxDuration: time.Second,
+		},
+		{
+			name:        "hook deadline overrides default",
+			defaultWait: time.Hour,
+			stop:        blocking,
+			opts:        []HookOption{WithTimeout(20 * time.Millisecond)},
+			wantErr:     "component did not stop within 20ms",
+			maxDuration: time.Second,
+		},
+		{
+			name:        "hook ignoring its deadline is abandoned",
+			defaultWait: 20 * time.Millisecond,
+			stop:        stuck,
+			wantErr:     "component did not stop within 20ms",
+			maxDuration: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.defaultWait)
+
+			var nextStopped bool
+			m.OnStop("next", func(context.Context) error {
+				nextStopped = true
+				return nil
+			})
+			m.OnStop("component", tt.stop, tt.opts...)
+
+			start := time.Now()
+			err := m.Shutdown()
+			if elapsed := time.Since(start); elapsed > tt.maxDuration {
+				t.Errorf("Shutdown took %s, want at most %s", elapsed, tt.maxDuration)
+			}
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Shutdown: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Shutdown error = %v, want %q", err, tt.wantErr)
+			}
+			if !nextStopped {
+				t.Error("hook registered before the component did not run")
+			}
+		})
+	}
+}
+
+func TestShutdownJoinsErrors(t *testing.T) {
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+
+	m := New(time.Second)
+	m.OnStop("a", func(context.Context) error { return errA })
+	m.OnStop("b", func(context.Context) error { return errB })
+
+	err := m.Shutdown()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Shutdown error = %v, want both hook errors", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	t.Run("context done", func(t *testing.T) {
+		m := New(time.Second)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := m.Wait(ctx); err != nil {
+			t.Errorf("Wait = %v, want nil", err)
+		}
+	})
+
+	t.Run("task fails", func(t *testing.T) {
+		m := New(time.Second)
+		errServe := errors.New("address in use")
+		m.Go("server", func() error { return errServe })
+
+		err := m.Wait(context.Background())
+		if !errors.Is(err, errServe) || !strings.HasPrefix(err.Error(), "server: ") {
+			t.Errorf("Wait = %v, want the server error", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+// Package metrics defines the Prometheus metrics of the service. They are
+// registered with Registry, which is served on /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "user_service"
+
+// Registry holds the metrics of the service and of the Go runtime.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// HTTPRequests counts HTTP requests by method, route pattern and status.
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestDuration observes the latency of HTTP requests by method,
+	// route pattern and status.
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// QueryDuration observes the duration of database queries by repository
+	// operation, e.g. "UserRepository.GetByID".
+	QueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries by repository operation and outcome.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "outcome"})
+
+	// IdempotencyRequests counts CreateUser calls with an idempotency key by
+	// result: "hit" replayed a stored result, "miss" created the user and
+	// "in_progress" was rejected because another request held the lock.
+	IdempotencyRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "idempotency",
+		Name:      "requests_total",
+		Help:      "Idempotent create requests by result (hit, miss, in_progress).",
+	}, []string{"result"})
+
+	// EventsPublished counts NATS publishes by event method and outcome.
+	EventsPublished = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nats",
+		Name:      "events_published_total",
+		Help:      "Events published to NATS by method and outcome (success, failure).",
+	}, []string{"method", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Outcome returns the outcome label of an operation that returned err.
+func Outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
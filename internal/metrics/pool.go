@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exposes the statistics of a pgx connection pool. They are
+// read when the metrics are scraped.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns         *prometheus.Desc
+	idleConns             *prometheus.Desc
+	constructingConns     *prometheus.Desc
+	totalConns            *prometheus.Desc
+	maxConns              *prometheus.Desc
+	acquires              *prometheus.Desc
+	acquireDuration       *prometheus.Desc
+	emptyAcquires         *prometheus.Desc
+	canceledAcquires      *prometheus.Desc
+	newConns              *prometheus.Desc
+	maxLifetimeDestroys   *prometheus.Desc
+	maxIdleTimeDestroys   *prometheus.Desc
+	emptyAcquireWaitTimes *prometheus.Desc
+}
+
+// RegisterPool exposes the statistics of pool.
+func RegisterPool(pool *pgxpool.Pool) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	Registry.MustRegister(&poolCollector{
+		pool:                  pool,
+		acquiredConns:         desc("acquired_connections", "Connections currently in use."),
+		idleConns:             desc("idle_connections", "Idle connections."),
+		constructingConns:     desc("constructing_connections", "Connections being established."),
+		totalConns:            desc("connections", "Open connections."),
+		maxConns:              desc("max_connections", "Maximum size of the pool."),
+		acquires:              desc("acquires_total", "Successful connection acquires."),
+		acquireDuration:       desc("acquire_duration_seconds_total", "Time spent acquiring connections."),
+		emptyAcquires:         desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquires:      desc("canceled_acquires_total", "Acquires canceled by their context."),
+		newConns:              desc("new_connections_total", "Connections opened."),
+		maxLifetimeDestroys:   desc("max_lifetime_destroys_total", "Connections closed because they reached their maximum lifetime."),
+		maxIdleTimeDestroys:   desc("max_idle_destroys_total", "Connections closed because they were idle for too long."),
+		emptyAcquireWaitTimes: desc("empty_acquire_wait_seconds_total", "Time spent waiting for a connection by empty acquires."),
+	})
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	gauge(c.constructingConns, float64(stat.ConstructingConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.maxConns, float64(stat.MaxConns()))
+	counter(c.acquires, float64(stat.AcquireCount()))
+	counter(c.acquireDuration, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquires, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquires, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.maxLifetimeDestroys, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.maxIdleTimeDestroys, float64(stat.MaxIdleDestroyCount()))
+	counter(c.emptyAcquireWaitTimes, stat.EmptyAcquireWaitTime().Seconds())
+}
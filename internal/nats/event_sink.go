@@ -5,18 +5,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/highway-to-Golang/user-service/internal/correlation"
+	"github.com/highway-to-Golang/user-service/internal/metrics"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
 type EventSink struct {
 	conn          *nats.Conn
 	subjectPrefix string
+	closed        chan struct{}
 }
 
 func New(url, subjectPrefix string) (*EventSink, error) {
-	conn, err := nats.Connect(url)
+	closed := make(chan struct{})
+	conn, err := nats.Connect(url, nats.ClosedHandler(func(*nats.Conn) { close(closed) }))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
 	}
@@ -24,24 +33,55 @@ func New(url, subjectPrefix string) (*EventSink, error) {
 	return &EventSink{
 		conn:          conn,
 		subjectPrefix: subjectPrefix,
+		closed:        closed,
 	}, nil
 }
 
-func (es *EventSink) Close() {
-	if es.conn != nil {
+// Drain flushes the buffered publishes and closes the connection. If ctx is
+// done first, the connection is closed and pending publishes are lost.
+func (es *EventSink) Drain(ctx context.Context) error {
+	if err := es.conn.Drain(); err != nil {
+		es.conn.Close()
+		return fmt.Errorf("failed to drain NATS connection: %w", err)
+	}
+
+	select {
+	case <-es.closed:
+		return nil
+	case <-ctx.Done():
 		es.conn.Close()
+		return fmt.Errorf("failed to drain NATS connection: %w", ctx.Err())
 	}
 }
 
+// Check reports whether the connection to NATS is established. While the
+// client reconnects, publishes are buffered and may be lost.
+func (es *EventSink) Check(context.Context) error {
+	if status := es.conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("NATS connection is %s", strings.ToLower(status.String()))
+	}
+	return nil
+}
+
 type Event struct {
-	Method    string    `json:"method"`
-	Timestamp time.Time `json:"timestamp"`
+	Method     string            `json:"method"`
+	UserID     string            `json:"user_id,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
 }
 
 func (es *EventSink) Publish(ctx context.Context, method string) error {
-	event := Event{
-		Method:    method,
-		Timestamp: time.Now(),
+	return es.PublishEvent(ctx, Event{Method: method})
+}
+
+// PublishEvent publishes an event that carries the affected user and extra
+// attributes. The subject is derived from the event method. Events published
+// while serving a request carry its X-Request-ID header, and the traceparent
+// header makes consumers part of the trace of the publish.
+func (es *EventSink) PublishEvent(ctx context.Context, event Event) error {
+	method := event.Method
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
 	}
 
 	data, err := json.Marshal(event)
@@ -50,10 +90,49 @@ func (es *EventSink) Publish(ctx context.Context, method string) error {
 	}
 
 	subject := fmt.Sprintf("%s.%s", es.subjectPrefix, method)
-	if err := es.conn.Publish(subject, data); err != nil {
-		slog.Error("failed to publish event", "error", err, "subject", subject)
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	if ids, ok := correlation.IDsFromContext(ctx); ok {
+		msg.Header.Set(correlation.RequestIDHeader, ids.RequestID)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+subject,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("nats"),
+			semconv.MessagingDestinationName(subject),
+		),
+	)
+	tracing.Inject(ctx, headerCarrier(msg.Header))
+
+	err = es.conn.PublishMsg(msg)
+	tracing.End(span, err)
+	metrics.EventsPublished.WithLabelValues(method, metrics.Outcome(err)).Inc()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to publish event", "error", err, "subject", subject)
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
 	return nil
 }
+
+// headerCarrier adapts nats.Header to propagation.TextMapCarrier. Unlike
+// http.Header, nats.Header does not canonicalize keys, so traceparent is
+// written as is.
+type headerCarrier nats.Header
+
+func (h headerCarrier) Get(key string) string {
+	return nats.Header(h).Get(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	nats.Header(h).Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+// LogNotifier writes notifications to the log instead of delivering them. It
+// is the default until a real delivery channel is configured.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Send(ctx context.Context, notification domain.Notification) error {
+	slog.InfoContext(ctx, "notification",
+		"to", notification.To,
+		"subject", notification.Subject,
+		"link", notification.Link,
+	)
+	return nil
+}
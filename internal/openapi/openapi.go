@@ -0,0 +1,305 @@
+// Package openapi embeds the OpenAPI 3.1 document of the HTTP API together
+// with an interactive explorer, and validates requests and responses against
+// the document.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	//go:embed openapi.json
+	document []byte
+	//go:embed explorer.html
+	explorer []byte
+)
+
+var (
+	ErrRouteNotFound    = errors.New("route is not documented")
+	ErrMethodNotAllowed = errors.New("method is not documented for route")
+)
+
+// Document returns the raw OpenAPI document.
+func Document() []byte {
+	return document
+}
+
+// Explorer returns the HTML page of the API explorer, which loads the
+// document from /openapi.json.
+func Explorer() []byte {
+	return explorer
+}
+
+// Spec is the subset of an OpenAPI document needed to validate requests and
+// responses.
+type Spec struct {
+	OpenAPI    string               `json:"openapi"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+
+	routes []route
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get"`
+	Put    *Operation `json:"put"`
+	Post   *Operation `json:"post"`
+	Delete *Operation `json:"delete"`
+	Patch  *Operation `json:"patch"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// route is a compiled path template. Templates may contain several
+// parameters and literals within one segment, e.g. /api/users/{id}:suspend.
+type route struct {
+	template string
+	pattern  *regexp.Regexp
+	literals int
+	item     *PathItem
+}
+
+// Load parses the embedded document.
+func Load() (*Spec, error) {
+	return Parse(document)
+}
+
+// MustLoad is like Load but panics if the embedded document is invalid, which
+// is a programming error.
+func MustLoad() *Spec {
+	spec, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+// Parse parses an OpenAPI document and checks that every schema reference
+// resolves.
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode openapi document: %w", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.1") {
+		return nil, fmt.Errorf("unsupported openapi version %q", spec.OpenAPI)
+	}
+
+	for template, item := range spec.Paths {
+		r, err := compileRoute(template)
+		if err != nil {
+			return nil, err
+		}
+		r.item = item
+		spec.routes = append(spec.routes, r)
+
+		for _, op := range item.operations() {
+			if err := spec.checkOperation(op); err != nil {
+				return nil, fmt.Errorf("%s: %w", template, err)
+			}
+		}
+	}
+
+	// The most specific template wins, like in http.ServeMux.
+	sort.Slice(spec.routes, func(i, j int) bool {
+		if spec.routes[i].literals != spec.routes[j].literals {
+			return spec.routes[i].literals > spec.routes[j].literals
+		}
+		return spec.routes[i].template < spec.routes[j].template
+	})
+
+	return &spec, nil
+}
+
+// Find returns the operation documented for a request method and path.
+func (s *Spec) Find(method, path string) (*Operation, error) {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+
+	matched := false
+	for _, r := range s.routes {
+		if !r.pattern.MatchString(path) {
+			continue
+		}
+		matched = true
+		if op := r.item.operation(method); op != nil {
+			return op, nil
+		}
+	}
+
+	if matched {
+		return nil, fmt.Errorf("%w: %s %s", ErrMethodNotAllowed, method, path)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrRouteNotFound, path)
+}
+
+func compileRoute(template string) (route, error) {
+	var pattern strings.Builder
+	literals := 0
+
+	pattern.WriteString("^")
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return route{}, fmt.Errorf("invalid path template %q", template)
+		}
+		pattern.WriteString(regexp.QuoteMeta(rest[:start]))
+		pattern.WriteString("[^/]+")
+		literals += start
+		rest = rest[start+end+1:]
+	}
+	pattern.WriteString(regexp.QuoteMeta(rest))
+	pattern.WriteString("$")
+	literals += len(rest)
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return route{}, fmt.Errorf("invalid path template %q: %w", template, err)
+	}
+	return route{template: template, pattern: re, literals: literals}, nil
+}
+
+func (p *PathItem) operation(method string) *Operation {
+	switch method {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPost:
+		return p.Post
+	case http.MethodDelete:
+		return p.Delete
+	case http.MethodPatch:
+		return p.Patch
+	default:
+		return nil
+	}
+}
+
+func (p *PathItem) operations() []*Operation {
+	var ops []*Operation
+	for _, op := range []*Operation{p.Get, p.Put, p.Post, p.Delete, p.Patch} {
+		if op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+func (s *Spec) checkOperation(op *Operation) error {
+	var schemas []*Schema
+	for _, p := range op.Parameters {
+		schemas = append(schemas, p.Schema)
+	}
+	if op.RequestBody != nil {
+		for _, m := range op.RequestBody.Content {
+			schemas = append(schemas, m.Schema)
+		}
+	}
+	for _, resp := range op.Responses {
+		for _, m := range resp.Content {
+			schemas = append(schemas, m.Schema)
+		}
+	}
+
+	for _, schema := range schemas {
+		if err := s.checkRefs(schema, map[*Schema]bool{}); err != nil {
+			return fmt.Errorf("%s: %w", op.OperationID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Spec) checkRefs(schema *Schema, seen map[*Schema]bool) error {
+	if schema == nil || seen[schema] {
+		return nil
+	}
+	seen[schema] = true
+
+	if schema.Ref != "" {
+		target, err := s.resolve(schema)
+		if err != nil {
+			return err
+		}
+		return s.checkRefs(target, seen)
+	}
+
+	children := []*Schema{schema.Items}
+	for _, p := range schema.Properties {
+		children = append(children, p)
+	}
+	if schema.AdditionalProperties != nil {
+		children = append(children, schema.AdditionalProperties.Schema)
+	}
+	children = append(children, schema.AllOf...)
+	children = append(children, schema.AnyOf...)
+	children = append(children, schema.OneOf...)
+
+	for _, child := range children {
+		if err := s.checkRefs(child, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const schemaRefPrefix = "#/components/schemas/"
+
+func (s *Spec) resolve(schema *Schema) (*Schema, error) {
+	for depth := 0; schema.Ref != ""; depth++ {
+		if depth > len(s.Components.Schemas) {
+			return nil, fmt.Errorf("circular reference %q", schema.Ref)
+		}
+		name, ok := strings.CutPrefix(schema.Ref, schemaRefPrefix)
+		if !ok {
+			return nil, fmt.Errorf("unsupported reference %q", schema.Ref)
+		}
+		target, ok := s.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %q", name)
+		}
+		schema = target
+	}
+	return schema, nil
+}
@@ -0,0 +1,246 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+// Schema is the subset of JSON Schema 2020-12 used by the document.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 Types              `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []interface{}      `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Additional        `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	AllOf                []*Schema          `json:"allOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+}
+
+// Types is the "type" keyword, which is either a single type or a list such
+// as ["string", "null"].
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = list
+	return nil
+}
+
+// Additional is the "additionalProperties" keyword: false forbids properties
+// that are not listed, a schema constrains them.
+type Additional struct {
+	Forbidden bool
+	Schema    *Schema
+}
+
+func (a *Additional) UnmarshalJSON(data []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(data, &allowed); err == nil {
+		a.Forbidden = !allowed
+		return nil
+	}
+	return json.Unmarshal(data, &a.Schema)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validateJSON decodes data and validates it against schema. Violations are
+// reported with field paths below prefix, e.g. "body.users[0].email".
+func (s *Spec) validateJSON(schema *Schema, data []byte, prefix string) []apperrors.Violation {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []apperrors.Violation{{Field: prefix, Message: "must be valid JSON"}}
+	}
+
+	var violations []apperrors.Violation
+	s.validate(schema, value, prefix, &violations)
+	return violations
+}
+
+func (s *Spec) validate(schema *Schema, value interface{}, field string, violations *[]apperrors.Violation) {
+	if schema == nil {
+		return
+	}
+	schema, err := s.resolve(schema)
+	if err != nil {
+		*violations = append(*violations, apperrors.Violation{Field: field, Message: err.Error()})
+		return
+	}
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, apperrors.Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(t string) bool { return hasType(value, t) }) {
+		report("must be of type %s", strings.Join(schema.Type, " or "))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e interface{}) bool { return equal(e, value) }) {
+		report("must be one of %v", schema.Enum)
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			report("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			report("must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.Format != "" && !hasFormat(v, schema.Format) {
+			report("must be a valid %s", schema.Format)
+		}
+
+	case json.Number:
+		n, _ := v.Float64()
+		if schema.Minimum != nil && n < *schema.Minimum {
+			report("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			report("must be at most %v", *schema.Maximum)
+		}
+
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, apperrors.Violation{Field: field + "." + name, Message: "is required"})
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if property, ok := schema.Properties[name]; ok {
+				s.validate(property, v[name], field+"."+name, violations)
+				continue
+			}
+			if additional := schema.AdditionalProperties; additional != nil {
+				if additional.Forbidden {
+					*violations = append(*violations, apperrors.Violation{Field: field + "." + name, Message: "is not allowed"})
+				} else {
+					s.validate(additional.Schema, v[name], field+"."+name, violations)
+				}
+			}
+		}
+
+	case []interface{}:
+		for i, item := range v {
+			s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), violations)
+		}
+	}
+
+	for _, sub := range schema.AllOf {
+		s.validate(sub, value, field, violations)
+	}
+	if len(schema.AnyOf) > 0 && s.countMatches(schema.AnyOf, value, field) == 0 {
+		report("must match at least one schema")
+	}
+	if len(schema.OneOf) > 0 && s.countMatches(schema.OneOf, value, field) != 1 {
+		report("must match exactly one schema")
+	}
+}
+
+func (s *Spec) countMatches(schemas []*Schema, value interface{}, field string) int {
+	matches := 0
+	for _, sub := range schemas {
+		var violations []apperrors.Violation
+		s.validate(sub, value, field, &violations)
+		if len(violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch v := value.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case json.Number:
+		if typ == "number" {
+			return true
+		}
+		n, err := v.Float64()
+		return typ == "integer" && err == nil && n == math.Trunc(n)
+	case map[string]interface{}:
+		return typ == "object"
+	case []interface{}:
+		return typ == "array"
+	default:
+		return false
+	}
+}
+
+// equal compares decoded JSON values, treating numbers by value since enums
+// in the document are decoded as float64.
+func equal(a, b interface{}) bool {
+	if n, ok := b.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return false
+		}
+		b = f
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func hasFormat(value, format string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.IsAbs()
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	default:
+		// Unknown formats are annotations only.
+		return true
+	}
+}
@@ -0,0 +1,176 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+// ErrSpecViolation marks a response that does not match the document, which
+// means that the handler and the document have drifted apart.
+var ErrSpecViolation = errors.New("response does not match the API specification")
+
+// ValidateRequest checks the query and header parameters and the body of a
+// request against op. The body must have been read by the caller.
+func (s *Spec) ValidateRequest(op *Operation, r *http.Request, body []byte) error {
+	var violations []apperrors.Violation
+
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "query":
+			values = r.URL.Query()[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		default:
+			continue
+		}
+
+		field := p.In + "." + p.Name
+		if len(values) == 0 {
+			if p.Required {
+				violations = append(violations, apperrors.Violation{Field: field, Message: "is required"})
+			}
+			continue
+		}
+		s.validate(p.Schema, parameterValue(p.Schema, values[0]), field, &violations)
+	}
+
+	if rb := op.RequestBody; rb != nil {
+		switch {
+		case len(body) == 0:
+			if rb.Required {
+				violations = append(violations, apperrors.Violation{Field: "body", Message: "is required"})
+			}
+		default:
+			mediaType := contentType(r.Header)
+			media, ok := lookupContent(rb.Content, mediaType)
+			if !ok {
+				return &apperrors.Error{
+					Code:    "unsupported_media_type",
+					Status:  http.StatusUnsupportedMediaType,
+					Message: fmt.Sprintf("content type must be one of %s", strings.Join(mediaTypes(rb.Content), ", ")),
+					Err:     apperrors.ErrInvalidInput,
+				}
+			}
+			if isJSON(mediaType) && media.Schema != nil {
+				violations = append(violations, s.validateJSON(media.Schema, body, "body")...)
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return apperrors.Validation("request does not match the API specification", violations...)
+	}
+	return nil
+}
+
+// ValidateResponse checks that the status of a response is documented for op
+// and that its content type and body match the documented response.
+func (s *Spec) ValidateResponse(op *Operation, status int, header http.Header, body []byte) error {
+	var violations []apperrors.Violation
+
+	resp := op.response(status)
+	switch {
+	case resp == nil:
+		violations = append(violations, apperrors.Violation{Field: "status", Message: fmt.Sprintf("%d is not documented", status)})
+	case len(resp.Content) > 0 && len(body) > 0:
+		mediaType := contentType(header)
+		media, ok := lookupContent(resp.Content, mediaType)
+		switch {
+		case !ok:
+			violations = append(violations, apperrors.Violation{
+				Field:   "header.Content-Type",
+				Message: fmt.Sprintf("%q is not one of %s", mediaType, strings.Join(mediaTypes(resp.Content), ", ")),
+			})
+		case isJSON(mediaType) && media.Schema != nil:
+			violations = append(violations, s.validateJSON(media.Schema, body, "body")...)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &apperrors.Error{
+		Code:       "spec_violation",
+		Status:     http.StatusInternalServerError,
+		Message:    fmt.Sprintf("%s (operation %s, status %d)", ErrSpecViolation, op.OperationID, status),
+		Violations: violations,
+		Err:        ErrSpecViolation,
+	}
+}
+
+// response returns the response documented for an exact status, a status
+// range such as "4XX" or the default response, in that order.
+func (op *Operation) response(status int) *Response {
+	if resp, ok := op.Responses[strconv.Itoa(status)]; ok {
+		return resp
+	}
+	if resp, ok := op.Responses[fmt.Sprintf("%dXX", status/100)]; ok {
+		return resp
+	}
+	return op.Responses["default"]
+}
+
+// parameterValue converts a query or header value to the JSON value it
+// represents according to schema, so that it can be validated against it.
+// Values that cannot be converted stay strings and fail the type check.
+func parameterValue(schema *Schema, raw string) interface{} {
+	if schema == nil {
+		return raw
+	}
+	if slices.Contains(schema.Type, "integer") || slices.Contains(schema.Type, "number") {
+		if _, err := strconv.ParseFloat(raw, 64); err == nil {
+			return json.Number(raw)
+		}
+	}
+	if slices.Contains(schema.Type, "boolean") && (raw == "true" || raw == "false") {
+		return raw == "true"
+	}
+	return raw
+}
+
+func contentType(header http.Header) string {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// lookupContent finds the media type in content, honouring ranges such as
+// "image/*".
+func lookupContent(content map[string]MediaType, mediaType string) (MediaType, bool) {
+	if media, ok := content[mediaType]; ok {
+		return media, true
+	}
+	for key, media := range content {
+		if key == "*/*" {
+			return media, true
+		}
+		if prefix, ok := strings.CutSuffix(key, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return media, true
+		}
+	}
+	return MediaType{}, false
+}
+
+func mediaTypes(content map[string]MediaType) []string {
+	types := make([]string, 0, len(content))
+	for key := range content {
+		types = append(types, key)
+	}
+	slices.Sort(types)
+	return types
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	activitySeenKey  = "activity:last_seen"
+	activityLoginKey = "activity:last_login"
+)
+
+// drainActivityScript reads and clears both activity hashes atomically, so
+// activity recorded while a flush is running ends up in the next flush.
+var drainActivityScript = redis.NewScript(`
+local seen = redis.call('HGETALL', KEYS[1])
+local login = redis.call('HGETALL', KEYS[2])
+redis.call('DEL', KEYS[1], KEYS[2])
+return {seen, login}
+`)
+
+// ActivityStorage buffers last-seen and last-login timestamps so that they can
+// be written to the database in batches instead of on every request. Each
+// hash maps a user ID to a unix timestamp in milliseconds.
+type ActivityStorage struct {
+	client *redis.Client
+}
+
+func NewActivityStorage(client *redis.Client) *ActivityStorage {
+	return &ActivityStorage{client: client}
+}
+
+// TouchSeen records that the user was seen at the given time unless that was
+// already recorded within throttle. It reports whether the time was recorded.
+func (s *ActivityStorage) TouchSeen(ctx context.Context, userID string, at time.Time, throttle time.Duration) (bool, error) {
+	ok, err := s.client.SetNX(ctx, fmt.Sprintf("activity_throttle:%s", userID), "1", throttle).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to throttle activity: %w", err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	if err := s.client.HSet(ctx, activitySeenKey, userID, at.UnixMilli()).Err(); err != nil {
+		return false, fmt.Errorf("failed to record activity: %w", err)
+	}
+
+	return true, nil
+}
+
+// RecordLogin records a login, which also counts as the user being seen.
+func (s *ActivityStorage) RecordLogin(ctx context.Context, userID string, at time.Time) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, activityLoginKey, userID, at.UnixMilli())
+		pipe.HSet(ctx, activitySeenKey, userID, at.UnixMilli())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+
+	return nil
+}
+
+// Drain removes and returns all buffered activity.
+func (s *ActivityStorage) Drain(ctx context.Context) ([]domain.Activity, error) {
+	res, err := drainActivityScript.Run(ctx, s.client, []string{activitySeenKey, activityLoginKey}).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to drain activity: %w", err)
+	}
+
+	seen, err := parseActivityHash(res[0])
+	if err != nil {
+		return nil, err
+	}
+	logins, err := parseActivityHash(res[1])
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]domain.Activity, 0, len(seen))
+	for userID, at := range seen {
+		a := domain.Activity{UserID: userID, LastSeenAt: at}
+		if login, ok := logins[userID]; ok {
+			a.LastLoginAt = &login
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, nil
+}
+
+// Restore puts drained activity back after a failed flush. Timestamps recorded
+// since the drain are newer and are kept.
+func (s *ActivityStorage) Restore(ctx context.Context, activity []domain.Activity) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, a := range activity {
+			pipe.HSetNX(ctx, activitySeenKey, a.UserID, a.LastSeenAt.UnixMilli())
+			if a.LastLoginAt != nil {
+				pipe.HSetNX(ctx, activityLoginKey, a.UserID, a.LastLoginAt.UnixMilli())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore activity: %w", err)
+	}
+
+	return nil
+}
+
+func parseActivityHash(v interface{}) (map[string]time.Time, error) {
+	fields, _ := v.([]interface{})
+
+	result := make(map[string]time.Time, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		userID, _ := fields[i].(string)
+		value, _ := fields[i+1].(string)
+
+		ms, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid activity timestamp for %s: %w", userID, err)
+		}
+		result[userID] = time.UnixMilli(ms)
+	}
+
+	return result, nil
+}
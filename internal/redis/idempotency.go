@@ -7,33 +7,24 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
 type IdempotencyStorage struct {
 	client *redis.Client
 }
 
-func NewIdempotencyStorage(url string) (*IdempotencyStorage, error) {
-	opt, err := redis.ParseURL(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse redis url: %w", err)
-	}
-
-	client := redis.NewClient(opt)
-
-	ctx := context.Background()
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to redis: %w", err)
-	}
-
-	return &IdempotencyStorage{client: client}, nil
+func NewIdempotencyStorage(client *redis.Client) *IdempotencyStorage {
+	return &IdempotencyStorage{client: client}
 }
 
-func (s *IdempotencyStorage) Close() error {
-	return s.client.Close()
-}
+func (s *IdempotencyStorage) GetResult(ctx context.Context, key string) (_ []byte, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyStorage.GetResult")
+	defer func() { tracing.End(span, err) }()
 
-func (s *IdempotencyStorage) GetResult(ctx context.Context, key string) ([]byte, error) {
 	data, err := s.client.Get(ctx, fmt.Sprintf("idempotency:%s", key)).Bytes()
 	if errors.Is(err, redis.Nil) {
 		return nil, nil
@@ -42,15 +33,32 @@ func (s *IdempotencyStorage) GetResult(ctx context.Context, key string) ([]byte,
 	return data, err
 }
 
-func (s *IdempotencyStorage) SaveResult(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+func (s *IdempotencyStorage) SaveResult(ctx context.Context, key string, value []byte, ttl time.Duration) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyStorage.SaveResult")
+	defer func() { tracing.End(span, err) }()
+
 	return s.client.Set(ctx, fmt.Sprintf("idempotency:%s", key), value, ttl).Err()
 }
 
-func (s *IdempotencyStorage) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+func (s *IdempotencyStorage) AcquireLock(ctx context.Context, key string, ttl time.Duration) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "IdempotencyStorage.AcquireLock")
+	defer func() { tracing.End(span, err) }()
+
 	result := s.client.SetNX(ctx, fmt.Sprintf("lock:%s", key), "1", ttl)
 	return result.Val(), result.Err()
 }
 
-func (s *IdempotencyStorage) ReleaseLock(ctx context.Context, key string) error {
+func (s *IdempotencyStorage) ReleaseLock(ctx context.Context, key string) (err error) {
+	ctx, span := startSpan(ctx, "IdempotencyStorage.ReleaseLock")
+	defer func() { tracing.End(span, err) }()
+
 	return s.client.Del(ctx, fmt.Sprintf("lock:%s", key)).Err()
 }
+
+// startSpan starts a client span for a call to Redis.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameRedis),
+	)
+}
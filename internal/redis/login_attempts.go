@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginAttemptStorage tracks failed logins in sliding windows. Every failure
+// is a member of a sorted set scored by its timestamp, so the window is
+// maintained by trimming members older than the window on each write.
+type LoginAttemptStorage struct {
+	client *redis.Client
+}
+
+func NewLoginAttemptStorage(client *redis.Client) *LoginAttemptStorage {
+	return &LoginAttemptStorage{client: client}
+}
+
+// RecordFailure adds a failure for scope (e.g. "account:<id>" or "ip:<addr>")
+// and returns the number of failures within the window.
+func (s *LoginAttemptStorage) RecordFailure(ctx context.Context, scope string, window time.Duration) (int64, error) {
+	key := fmt.Sprintf("login_attempts:%s", scope)
+	now := time.Now()
+
+	var card *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Add(-window).UnixNano(), 10))
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixNano()), Member: uuid.NewString()})
+		card = pipe.ZCard(ctx, key)
+		pipe.PExpire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return card.Val(), nil
+}
+
+// SetBackoff blocks further attempts for scope for the given duration.
+func (s *LoginAttemptStorage) SetBackoff(ctx context.Context, scope string, d time.Duration) error {
+	return s.client.Set(ctx, fmt.Sprintf("login_backoff:%s", scope), "1", d).Err()
+}
+
+// Backoff returns how long attempts for scope are still blocked, or zero.
+func (s *LoginAttemptStorage) Backoff(ctx context.Context, scope string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, fmt.Sprintf("login_backoff:%s", scope)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login backoff: %w", err)
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// Reset clears failures and backoff for scope.
+func (s *LoginAttemptStorage) Reset(ctx context.Context, scope string) error {
+	return s.client.Del(ctx,
+		fmt.Sprintf("login_attempts:%s", scope),
+		fmt.Sprintf("login_backoff:%s", scope),
+	).Err()
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PersistedQueryStorage stores GraphQL queries by their SHA-256 hash, so that
+// clients can send the hash instead of the full query.
+type PersistedQueryStorage struct {
+	client *redis.Client
+}
+
+func NewPersistedQueryStorage(client *redis.Client) *PersistedQueryStorage {
+	return &PersistedQueryStorage{client: client}
+}
+
+// Get returns the query stored under hash, or "" if there is none.
+func (s *PersistedQueryStorage) Get(ctx context.Context, hash string) (string, error) {
+	query, err := s.client.Get(ctx, fmt.Sprintf("persisted_query:%s", hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+
+	return query, err
+}
+
+// Save stores a query and extends its lifetime if it is already stored.
+func (s *PersistedQueryStorage) Save(ctx context.Context, hash, query string, ttl time.Duration) error {
+	return s.client.Set(ctx, fmt.Sprintf("persisted_query:%s", hash), query, ttl).Err()
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// rateLimitScript implements GCRA for several limits at once. Each key holds
+// the theoretical arrival time (TAT) of the next request in milliseconds. A
+// request is only counted if every limit allows it, so a request rejected by
+// one limit does not use up the others. The clock of Redis is used, so that
+// all instances agree on the time.
+//
+// ARGV holds the emission interval and the period of each key in
+// milliseconds. The result holds the remaining requests, the time until the
+// limit is fully reset and the time to wait before retrying for each key.
+var rateLimitScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local result = {}
+local tats = {}
+local allowed = true
+for i, key in ipairs(KEYS) do
+	local interval = tonumber(ARGV[i * 2 - 1])
+	local period = tonumber(ARGV[i * 2])
+	local tat = math.max(tonumber(redis.call('GET', key)) or now, now)
+	local next_tat = tat + interval
+	local wait = next_tat - period - now
+	if wait > 0 then
+		allowed = false
+		table.insert(result, 0)
+		table.insert(result, tat - now)
+		table.insert(result, wait)
+	else
+		table.insert(result, math.floor((period - (next_tat - now)) / interval))
+		table.insert(result, next_tat - now)
+		table.insert(result, 0)
+	end
+	tats[i] = next_tat
+end
+
+if allowed then
+	for i, key in ipairs(KEYS) do
+		redis.call('SET', key, tats[i], 'PX', tats[i] - now)
+	end
+end
+
+return result
+`)
+
+// RateLimitStorage enforces rate limits shared by all instances.
+type RateLimitStorage struct {
+	client *redis.Client
+}
+
+func NewRateLimitStorage(client *redis.Client) *RateLimitStorage {
+	return &RateLimitStorage{client: client}
+}
+
+// Allow checks a request against limits and counts it if all of them allow
+// it. It returns the state of every limit in the same order.
+func (s *RateLimitStorage) Allow(ctx context.Context, limits []domain.RateLimit) ([]domain.RateLimitState, error) {
+	keys := make([]string, 0, len(limits))
+	args := make([]interface{}, 0, 2*len(limits))
+	for _, limit := range limits {
+		keys = append(keys, fmt.Sprintf("rate_limit:%s", limit.Key))
+		interval := max(limit.Period.Milliseconds()/int64(limit.Requests), 1)
+		args = append(args, interval, limit.Period.Milliseconds())
+	}
+
+	values, err := rateLimitScript.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if len(values) != 3*len(limits) {
+		return nil, fmt.Errorf("failed to check rate limit: unexpected result of %d values", len(values))
+	}
+
+	states := make([]domain.RateLimitState, len(limits))
+	for i := range states {
+		states[i] = domain.RateLimitState{
+			Remaining:  int(values[3*i]),
+			Reset:      time.Duration(values[3*i+1]) * time.Millisecond,
+			RetryAfter: time.Duration(values[3*i+2]) * time.Millisecond,
+		}
+	}
+
+	return states, nil
+}
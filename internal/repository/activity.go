@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+// lastActiveAt is the most recent sign of life of a user. Users who never
+// logged in are considered active as of their creation.
+var lastActiveAt = goqu.COALESCE(goqu.C("last_seen_at"), goqu.C("last_login_at"), goqu.C("created_at"))
+
+// recordActivityQuery is written by hand because goqu expands slice arguments
+// into lists instead of binding them as arrays.
+const recordActivityQuery = `
+UPDATE users AS u SET
+	last_seen_at = GREATEST(u.last_seen_at, a.seen_at),
+	last_login_at = GREATEST(u.last_login_at, a.login_at),
+	inactivity_warned_at = NULL
+FROM unnest($1::varchar[], $2::timestamptz[], $3::timestamptz[]) AS a(id, seen_at, login_at)
+WHERE u.id = a.id`
+
+// RecordActivity stores a batch of activity in one statement. Timestamps only
+// move forward, so batches flushed out of order cannot rewind them, and any
+// activity clears a pending inactivity warning. updated_at is left alone as
+// activity is not a change of the user.
+func (r *UserRepository) RecordActivity(ctx context.Context, activity []domain.Activity) error {
+	if len(activity) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(activity))
+	seen := make([]time.Time, 0, len(activity))
+	logins := make([]*time.Time, 0, len(activity))
+	for _, a := range activity {
+		ids = append(ids, a.UserID)
+		seen = append(seen, a.LastSeenAt)
+		logins = append(logins, a.LastLoginAt)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, recordActivityQuery, ids, seen, logins)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record activity", "error", err, "count", len(activity))
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+
+	slog.DebugContext(ctx, "activity recorded", "count", len(activity), "updated", result.RowsAffected())
+	return nil
+}
+
+// ListInactive returns active users without any activity since before, oldest
+// first.
+func (r *UserRepository) ListInactive(ctx context.Context, before time.Time) ([]domain.InactiveUser, error) {
+	query, args, err := r.goqu.From("users").
+		Select("id", "email", lastActiveAt.As("last_active_at"), "inactivity_warned_at").
+		Where(
+			goqu.C("status").Eq(domain.StatusActive),
+			lastActiveAt.Lt(before),
+		).
+		Order(lastActiveAt.Asc()).
+		ToSQL()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select inactive users query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get inactive users", "error", err)
+		return nil, fmt.Errorf("failed to get inactive users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.InactiveUser
+	for rows.Next() {
+		var user domain.InactiveUser
+		if err := rows.Scan(&user.ID, &user.Email, &user.LastActiveAt, &user.WarnedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inactive user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return users, nil
+}
+
+// MarkInactivityWarned records that the user was warned about inactivity. It
+// is cleared again by RecordActivity.
+func (r *UserRepository) MarkInactivityWarned(ctx context.Context, id string, at time.Time) error {
+	query, args, err := r.goqu.Update("users").
+		Set(goqu.Record{"inactivity_warned_at": at}).
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build mark inactivity warned query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to mark inactivity warning", "error", err, "user_id", id)
+		return fmt.Errorf("failed to mark inactivity warning: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type EmailVerificationRepository struct {
+	db   *database.DB
+	goqu *goqu.Database
+}
+
+func NewEmailVerificationRepository(db *database.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		db:   db,
+		goqu: goqu.New("postgres", nil),
+	}
+}
+
+func (r *EmailVerificationRepository) Create(ctx context.Context, verification domain.EmailVerification) error {
+	query, args, err := r.goqu.Insert("email_verifications").
+		Rows(goqu.Record{
+			"token_hash": verification.TokenHash,
+			"user_id":    verification.UserID,
+			"email":      verification.Email,
+			"expires_at": verification.ExpiresAt,
+			"created_at": time.Now(),
+		}).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build insert email verification query", "error", err)
+		return fmt.Errorf("failed to build insert email verification query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to create email verification", "error", err, "user_id", verification.UserID)
+		return fmt.Errorf("failed to create email verification: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an unexpired verification token as used and returns it. A
+// token can only be consumed once; later attempts return domain.ErrNotFound.
+func (r *EmailVerificationRepository) Consume(ctx context.Context, tokenHash string) (domain.EmailVerification, error) {
+	now := time.Now()
+
+	query, args, err := r.goqu.Update("email_verifications").
+		Set(goqu.Record{"used_at": now}).
+		Where(
+			goqu.C("token_hash").Eq(tokenHash),
+			goqu.C("used_at").IsNull(),
+			goqu.C("expires_at").Gt(now),
+		).
+		Returning("token_hash", "user_id", "email", "expires_at", "created_at").
+		ToSQL()
+
+	if err != nil {
+		return domain.EmailVerification{}, fmt.Errorf("failed to build consume email verification query: %w", err)
+	}
+
+	var verification domain.EmailVerification
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(
+		&verification.TokenHash,
+		&verification.UserID,
+		&verification.Email,
+		&verification.ExpiresAt,
+		&verification.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.EmailVerification{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to consume email verification", "error", err)
+		return domain.EmailVerification{}, fmt.Errorf("failed to consume email verification: %w", err)
+	}
+
+	return verification, nil
+}
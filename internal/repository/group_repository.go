@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type GroupRepository struct {
+	db   *database.DB
+	goqu *goqu.Database
+}
+
+func NewGroupRepository(db *database.DB) *GroupRepository {
+	return &GroupRepository{
+		db:   db,
+		goqu: goqu.New("postgres", nil),
+	}
+}
+
+func (r *GroupRepository) Create(ctx context.Context, group domain.Group) error {
+	now := time.Now()
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query, args, err := r.goqu.Insert("groups").
+		Rows(goqu.Record{
+			"id":         group.ID,
+			"name":       group.Name,
+			"created_at": now,
+			"updated_at": now,
+		}).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build insert group query", "error", err)
+		return fmt.Errorf("failed to build insert group query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to create group: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to create group", "error", err, "group_id", group.ID)
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	if err := r.replaceMembers(ctx, tx, group.ID, group.MemberIDs()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "group created successfully", "group_id", group.ID)
+	return nil
+}
+
+func (r *GroupRepository) GetByID(ctx context.Context, id string) (domain.Group, error) {
+	query, args, err := r.goqu.From("groups").
+		Select("id", "name", "created_at", "updated_at").
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select group query", "error", err)
+		return domain.Group{}, fmt.Errorf("failed to build select group query: %w", err)
+	}
+
+	var group domain.Group
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&group.ID, &group.Name, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Group{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get group", "error", err, "group_id", id)
+		return domain.Group{}, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	members, err := r.GetMembers(ctx, []string{group.ID})
+	if err != nil {
+		return domain.Group{}, err
+	}
+	group.Members = members[group.ID]
+
+	return group, nil
+}
+
+func (r *GroupRepository) GetAll(ctx context.Context) ([]domain.Group, error) {
+	query, args, err := r.goqu.From("groups").
+		Select("id", "name", "created_at", "updated_at").
+		Order(goqu.C("name").Asc()).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select groups query", "error", err)
+		return nil, fmt.Errorf("failed to build select groups query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get groups", "error", err)
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []domain.Group
+	var ids []string
+	for rows.Next() {
+		var group domain.Group
+		if err := rows.Scan(&group.ID, &group.Name, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+		groups = append(groups, group)
+		ids = append(ids, group.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	members, err := r.GetMembers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Members = members[groups[i].ID]
+	}
+
+	return groups, nil
+}
+
+// GetByUserIDs returns the groups of every given user keyed by user ID.
+func (r *GroupRepository) GetByUserIDs(ctx context.Context, userIDs []string) (map[string][]domain.Group, error) {
+	result := make(map[string][]domain.Group, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	query, args, err := r.goqu.From(goqu.T("group_members").As("gm")).
+		Join(goqu.T("groups").As("g"), goqu.On(goqu.I("g.id").Eq(goqu.I("gm.group_id")))).
+		Select("gm.user_id", "g.id", "g.name", "g.created_at", "g.updated_at").
+		Where(goqu.I("gm.user_id").In(userIDs)).
+		Order(goqu.I("g.name").Asc()).
+		ToSQL()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select user groups query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get user groups", "error", err)
+		return nil, fmt.Errorf("failed to get user groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var group domain.Group
+		if err := rows.Scan(&userID, &group.ID, &group.Name, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user group: %w", err)
+		}
+		result[userID] = append(result[userID], group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *GroupRepository) Update(ctx context.Context, group domain.Group) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query, args, err := r.goqu.Update("groups").
+		Set(goqu.Record{
+			"name":       group.Name,
+			"updated_at": time.Now(),
+		}).
+		Where(goqu.C("id").Eq(group.ID)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build update group query", "error", err)
+		return fmt.Errorf("failed to build update group query: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to update group: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to update group", "error", err, "group_id", group.ID)
+		return fmt.Errorf("failed to update group: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	if err := r.replaceMembers(ctx, tx, group.ID, group.MemberIDs()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "group updated successfully", "group_id", group.ID)
+	return nil
+}
+
+// AddMember adds a user to a group. Adding an existing member is a no-op.
+func (r *GroupRepository) AddMember(ctx context.Context, groupID, userID string) error {
+	query, args, err := r.goqu.Insert("group_members").
+		Rows(goqu.Record{
+			"group_id":   groupID,
+			"user_id":    userID,
+			"created_at": time.Now(),
+		}).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert member query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		if isForeignKeyViolation(err) {
+			return domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to add group member", "error", err, "group_id", groupID, "user_id", userID)
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	return nil
+}
+
+func (r *GroupRepository) Delete(ctx context.Context, id string) error {
+	query, args, err := r.goqu.Delete("groups").
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete group query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete group", "error", err, "group_id", id)
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	slog.InfoContext(ctx, "group deleted successfully", "group_id", id)
+	return nil
+}
+
+func (r *GroupRepository) replaceMembers(ctx context.Context, tx pgx.Tx, groupID string, userIDs []string) error {
+	query, args, err := r.goqu.Delete("group_members").
+		Where(goqu.C("group_id").Eq(groupID)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete members query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete group members: %w", err)
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		rows = append(rows, goqu.Record{
+			"group_id":   groupID,
+			"user_id":    userID,
+			"created_at": now,
+		})
+	}
+
+	query, args, err = r.goqu.Insert("group_members").Rows(rows...).OnConflict(goqu.DoNothing()).ToSQL()
+	if err != nil {
+		return fmt.Errorf("failed to build insert members query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("%w: unknown group member", domain.ErrInvalidInput)
+		}
+		return fmt.Errorf("failed to insert group members: %w", err)
+	}
+
+	return nil
+}
+
+// GetMembers returns the members of every given group keyed by group ID.
+func (r *GroupRepository) GetMembers(ctx context.Context, groupIDs []string) (map[string][]domain.GroupMember, error) {
+	result := make(map[string][]domain.GroupMember, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	query, args, err := r.goqu.From(goqu.T("group_members").As("gm")).
+		Join(goqu.T("users").As("u"), goqu.On(goqu.I("u.id").Eq(goqu.I("gm.user_id")))).
+		Select("gm.group_id", "u.id", "u.name").
+		Where(goqu.I("gm.group_id").In(groupIDs)).
+		Order(goqu.I("u.name").Asc()).
+		ToSQL()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select members query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get group members", "error", err)
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID string
+		var member domain.GroupMember
+		if err := rows.Scan(&groupID, &member.UserID, &member.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+		result[groupID] = append(result[groupID], member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return result, nil
+}
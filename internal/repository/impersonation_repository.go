@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+type ImpersonationRepository struct {
+	db   *database.DB
+	goqu *goqu.Database
+}
+
+func NewImpersonationRepository(db *database.DB) *ImpersonationRepository {
+	return &ImpersonationRepository{
+		db:   db,
+		goqu: goqu.New("postgres", nil),
+	}
+}
+
+func (r *ImpersonationRepository) Create(ctx context.Context, session domain.ImpersonationSession) error {
+	query, args, err := r.goqu.Insert("impersonation_sessions").
+		Rows(goqu.Record{
+			"id":         session.ID,
+			"actor_id":   session.ActorID,
+			"user_id":    session.UserID,
+			"reason":     session.Reason,
+			"ip":         session.IP,
+			"created_at": session.CreatedAt,
+			"expires_at": session.ExpiresAt,
+		}).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build insert impersonation session query", "error", err)
+		return fmt.Errorf("failed to build insert impersonation session query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to create impersonation session", "error", err, "session_id", session.ID)
+		return fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	return nil
+}
+
+// List returns impersonation sessions, newest first, optionally filtered by
+// the impersonated user.
+func (r *ImpersonationRepository) List(ctx context.Context, userID string) ([]domain.ImpersonationSession, error) {
+	ds := r.sessions()
+	if userID != "" {
+		ds = ds.Where(goqu.C("user_id").Eq(userID))
+	}
+
+	return r.list(ctx, ds)
+}
+
+// ListByUserIDs returns the impersonation sessions of every given user keyed
+// by user ID, newest first.
+func (r *ImpersonationRepository) ListByUserIDs(ctx context.Context, userIDs []string) (map[string][]domain.ImpersonationSession, error) {
+	result := make(map[string][]domain.ImpersonationSession, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	sessions, err := r.list(ctx, r.sessions().Where(goqu.C("user_id").In(userIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		result[s.UserID] = append(result[s.UserID], s)
+	}
+
+	return result, nil
+}
+
+func (r *ImpersonationRepository) sessions() *goqu.SelectDataset {
+	return r.goqu.From("impersonation_sessions").
+		Select("id", "actor_id", "user_id", "reason", "ip", "created_at", "expires_at").
+		Order(goqu.C("created_at").Desc())
+}
+
+func (r *ImpersonationRepository) list(ctx context.Context, ds *goqu.SelectDataset) ([]domain.ImpersonationSession, error) {
+	query, args, err := ds.ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select impersonation sessions query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get impersonation sessions", "error", err)
+		return nil, fmt.Errorf("failed to get impersonation sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []domain.ImpersonationSession
+	for rows.Next() {
+		var s domain.ImpersonationSession
+		if err := rows.Scan(&s.ID, &s.ActorID, &s.UserID, &s.Reason, &s.IP, &s.CreatedAt, &s.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan impersonation session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return sessions, nil
+}
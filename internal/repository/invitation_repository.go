@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+var invitationColumns = []interface{}{
+	"id", "email", "role", "group_ids", "status", "token_hash", "invited_by", "user_id",
+	"expires_at", "accepted_at", "created_at", "updated_at",
+}
+
+func invitationScanTargets(invitation *domain.Invitation) []interface{} {
+	return []interface{}{
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.GroupIDs,
+		&invitation.Status,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.UserID,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	}
+}
+
+type InvitationRepository struct {
+	db   *database.DB
+	goqu *goqu.Database
+}
+
+func NewInvitationRepository(db *database.DB) *InvitationRepository {
+	return &InvitationRepository{
+		db:   db,
+		goqu: goqu.New("postgres", nil),
+	}
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, invitation domain.Invitation) error {
+	groupIDs, err := json.Marshal(invitation.GroupIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode group ids: %w", err)
+	}
+
+	now := time.Now()
+	query, args, err := r.goqu.Insert("invitations").
+		Rows(goqu.Record{
+			"id":         invitation.ID,
+			"email":      invitation.Email,
+			"role":       invitation.Role,
+			"group_ids":  string(groupIDs),
+			"status":     invitation.Status,
+			"token_hash": invitation.TokenHash,
+			"invited_by": invitation.InvitedBy,
+			"expires_at": invitation.ExpiresAt,
+			"created_at": now,
+			"updated_at": now,
+		}).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build insert invitation query", "error", err)
+		return fmt.Errorf("failed to build insert invitation query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to create invitation: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to create invitation", "error", err, "invitation_id", invitation.ID)
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	slog.InfoContext(ctx, "invitation created successfully", "invitation_id", invitation.ID)
+	return nil
+}
+
+func (r *InvitationRepository) GetByID(ctx context.Context, id string) (domain.Invitation, error) {
+	query, args, err := r.goqu.From("invitations").
+		Select(invitationColumns...).
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select invitation query", "error", err)
+		return domain.Invitation{}, fmt.Errorf("failed to build select invitation query: %w", err)
+	}
+
+	var invitation domain.Invitation
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(invitationScanTargets(&invitation)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Invitation{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get invitation", "error", err, "invitation_id", id)
+		return domain.Invitation{}, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// GetAll returns invitations, newest first, optionally filtered by status.
+func (r *InvitationRepository) GetAll(ctx context.Context, status string) ([]domain.Invitation, error) {
+	ds := r.goqu.From("invitations").
+		Select(invitationColumns...).
+		Order(goqu.C("created_at").Desc())
+	if status != "" {
+		ds = ds.Where(goqu.C("status").Eq(status))
+	}
+
+	query, args, err := ds.ToSQL()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select invitations query", "error", err)
+		return nil, fmt.Errorf("failed to build select invitations query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get invitations", "error", err)
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []domain.Invitation
+	for rows.Next() {
+		var invitation domain.Invitation
+		if err := rows.Scan(invitationScanTargets(&invitation)...); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// Renew replaces the token of a pending or expired invitation and makes it
+// pending again until expiresAt. Tokens issued before are no longer valid.
+func (r *InvitationRepository) Renew(ctx context.Context, id, tokenHash string, expiresAt time.Time) error {
+	query, args, err := r.goqu.Update("invitations").
+		Set(goqu.Record{
+			"token_hash": tokenHash,
+			"status":     domain.InvitationPending,
+			"expires_at": expiresAt,
+			"updated_at": time.Now(),
+		}).
+		Where(
+			goqu.C("id").Eq(id),
+			goqu.C("status").In(domain.InvitationPending, domain.InvitationExpired),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build renew invitation query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to renew invitation: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to renew invitation", "error", err, "invitation_id", id)
+		return fmt.Errorf("failed to renew invitation: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// UpdateStatus moves an invitation from one status to another. It returns
+// domain.ErrInvitationNotPending when the invitation is no longer in from.
+func (r *InvitationRepository) UpdateStatus(ctx context.Context, id, from, to string) error {
+	query, args, err := r.goqu.Update("invitations").
+		Set(goqu.Record{
+			"status":     to,
+			"updated_at": time.Now(),
+		}).
+		Where(
+			goqu.C("id").Eq(id),
+			goqu.C("status").Eq(from),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update invitation status query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update invitation status", "error", err, "invitation_id", id)
+		return fmt.Errorf("failed to update invitation status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// Claim marks a pending, unexpired invitation with the given token as
+// accepted. A token can only be claimed once; later attempts return
+// domain.ErrInvitationNotPending.
+func (r *InvitationRepository) Claim(ctx context.Context, id, tokenHash string) error {
+	now := time.Now()
+
+	query, args, err := r.goqu.Update("invitations").
+		Set(goqu.Record{
+			"status":      domain.InvitationAccepted,
+			"accepted_at": now,
+			"updated_at":  now,
+		}).
+		Where(
+			goqu.C("id").Eq(id),
+			goqu.C("token_hash").Eq(tokenHash),
+			goqu.C("status").Eq(domain.InvitationPending),
+			goqu.C("expires_at").Gt(now),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build claim invitation query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to claim invitation", "error", err, "invitation_id", id)
+		return fmt.Errorf("failed to claim invitation: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// Release returns a claimed invitation to pending, e.g. when creating the
+// invited user failed.
+func (r *InvitationRepository) Release(ctx context.Context, id string) error {
+	query, args, err := r.goqu.Update("invitations").
+		Set(goqu.Record{
+			"status":      domain.InvitationPending,
+			"accepted_at": nil,
+			"updated_at":  time.Now(),
+		}).
+		Where(
+			goqu.C("id").Eq(id),
+			goqu.C("status").Eq(domain.InvitationAccepted),
+			goqu.C("user_id").Eq(""),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build release invitation query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to release invitation", "error", err, "invitation_id", id)
+		return fmt.Errorf("failed to release invitation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InvitationRepository) SetUserID(ctx context.Context, id, userID string) error {
+	query, args, err := r.goqu.Update("invitations").
+		Set(goqu.Record{
+			"user_id":    userID,
+			"updated_at": time.Now(),
+		}).
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update invitation user query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to update invitation user", "error", err, "invitation_id", id)
+		return fmt.Errorf("failed to update invitation user: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireStale marks pending invitations past their expiry as expired and
+// returns their IDs.
+func (r *InvitationRepository) ExpireStale(ctx context.Context, now time.Time) ([]string, error) {
+	query, args, err := r.goqu.Update("invitations").
+		Set(goqu.Record{
+			"status":     domain.InvitationExpired,
+			"updated_at": now,
+		}).
+		Where(
+			goqu.C("status").Eq(domain.InvitationPending),
+			goqu.C("expires_at").Lte(now),
+		).
+		Returning("id").
+		ToSQL()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build expire invitations query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to expire invitations", "error", err)
+		return nil, fmt.Errorf("failed to expire invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan invitation id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return ids, nil
+}
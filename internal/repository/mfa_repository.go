@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type MFARepository struct {
+	db   *database.DB
+	goqu *goqu.Database
+}
+
+func NewMFARepository(db *database.DB) *MFARepository {
+	return &MFARepository{
+		db:   db,
+		goqu: goqu.New("postgres", nil),
+	}
+}
+
+func (r *MFARepository) Get(ctx context.Context, userID string) (domain.MFA, error) {
+	query, args, err := r.goqu.From("user_mfa").
+		Select("user_id", "secret", "enabled", "last_used_step", "confirmed_at", "created_at").
+		Where(goqu.C("user_id").Eq(userID)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select mfa query", "error", err)
+		return domain.MFA{}, fmt.Errorf("failed to build select mfa query: %w", err)
+	}
+
+	var mfa domain.MFA
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(
+		&mfa.UserID,
+		&mfa.Secret,
+		&mfa.Enabled,
+		&mfa.LastUsedStep,
+		&mfa.ConfirmedAt,
+		&mfa.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.MFA{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get mfa", "error", err, "user_id", userID)
+		return domain.MFA{}, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	return mfa, nil
+}
+
+// SavePending stores a new unconfirmed secret. An already enabled MFA is left
+// untouched, in which case domain.ErrMFAAlreadyEnabled is returned.
+func (r *MFARepository) SavePending(ctx context.Context, userID, secret string) error {
+	query, args, err := r.goqu.Insert("user_mfa").
+		Rows(goqu.Record{
+			"user_id":        userID,
+			"secret":         secret,
+			"enabled":        false,
+			"last_used_step": 0,
+			"created_at":     time.Now(),
+		}).
+		OnConflict(goqu.DoUpdate("user_id", goqu.Record{
+			"secret":         goqu.I("excluded.secret"),
+			"last_used_step": 0,
+			"created_at":     goqu.I("excluded.created_at"),
+		}).Where(goqu.I("user_mfa.enabled").IsFalse())).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build upsert mfa query", "error", err)
+		return fmt.Errorf("failed to build upsert mfa query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to save pending mfa", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to save pending mfa: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	return nil
+}
+
+// Enable marks the pending secret as confirmed and replaces the recovery codes
+// in a single transaction.
+func (r *MFARepository) Enable(ctx context.Context, userID string, step int64, codeHashes []string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query, args, err := r.goqu.Update("user_mfa").
+		Set(goqu.Record{
+			"enabled":        true,
+			"last_used_step": step,
+			"confirmed_at":   time.Now(),
+		}).
+		Where(goqu.C("user_id").Eq(userID), goqu.C("enabled").IsFalse()).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build enable mfa query: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to enable mfa", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+
+	if err := r.replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "mfa enabled", "user_id", userID)
+	return nil
+}
+
+func (r *MFARepository) replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, codeHashes []string) error {
+	query, args, err := r.goqu.Delete("user_mfa_recovery_codes").
+		Where(goqu.C("user_id").Eq(userID)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete recovery codes query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]interface{}, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		rows = append(rows, goqu.Record{
+			"user_id":    userID,
+			"code_hash":  hash,
+			"created_at": now,
+		})
+	}
+
+	query, args, err = r.goqu.Insert("user_mfa_recovery_codes").Rows(rows...).ToSQL()
+	if err != nil {
+		return fmt.Errorf("failed to build insert recovery codes query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to insert recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// AdvanceStep records step as the last used TOTP step. It returns false when
+// the step is not newer than the stored one, which means the code is a replay.
+func (r *MFARepository) AdvanceStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query, args, err := r.goqu.Update("user_mfa").
+		Set(goqu.Record{"last_used_step": step}).
+		Where(goqu.C("user_id").Eq(userID), goqu.C("last_used_step").Lt(step)).
+		ToSQL()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to build advance step query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to advance mfa step", "error", err, "user_id", userID)
+		return false, fmt.Errorf("failed to advance mfa step: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode consumes an unused recovery code. It returns false when no
+// matching unused code exists.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	query, args, err := r.goqu.Update("user_mfa_recovery_codes").
+		Set(goqu.Record{"used_at": time.Now()}).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("code_hash").Eq(codeHash),
+			goqu.C("used_at").IsNull(),
+		).
+		ToSQL()
+
+	if err != nil {
+		return false, fmt.Errorf("failed to build use recovery code query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to use recovery code", "error", err, "user_id", userID)
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+func (r *MFARepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := r.replaceRecoveryCodes(ctx, tx, userID, nil); err != nil {
+		return err
+	}
+
+	query, args, err := r.goqu.Delete("user_mfa").
+		Where(goqu.C("user_id").Eq(userID)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete mfa query: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete mfa", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to delete mfa: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "mfa deleted", "user_id", userID)
+	return nil
+}
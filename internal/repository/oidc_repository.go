@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/database"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+type OIDCRepository struct {
+	db   *database.DB
+	goqu *goqu.Database
+}
+
+func NewOIDCRepository(db *database.DB) *OIDCRepository {
+	return &OIDCRepository{
+		db:   db,
+		goqu: goqu.New("postgres", nil),
+	}
+}
+
+func (r *OIDCRepository) CreateClient(ctx context.Context, client domain.OIDCClient) error {
+	redirectURIs, err := json.Marshal(client.RedirectURIs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redirect uris: %w", err)
+	}
+
+	query, args, err := r.goqu.Insert("oidc_clients").
+		Rows(goqu.Record{
+			"id":            client.ID,
+			"name":          client.Name,
+			"secret_hash":   client.SecretHash,
+			"redirect_uris": string(redirectURIs),
+			"public":        client.Public,
+			"created_at":    client.CreatedAt,
+		}).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build insert client query", "error", err)
+		return fmt.Errorf("failed to build insert client query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to create oidc client", "error", err, "client_id", client.ID)
+		return fmt.Errorf("failed to create oidc client: %w", err)
+	}
+
+	slog.InfoContext(ctx, "oidc client created", "client_id", client.ID)
+	return nil
+}
+
+func (r *OIDCRepository) GetClient(ctx context.Context, id string) (domain.OIDCClient, error) {
+	query, args, err := r.goqu.From("oidc_clients").
+		Select("id", "name", "secret_hash", "redirect_uris", "public", "created_at").
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select client query", "error", err)
+		return domain.OIDCClient{}, fmt.Errorf("failed to build select client query: %w", err)
+	}
+
+	var client domain.OIDCClient
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(
+		&client.ID,
+		&client.Name,
+		&client.SecretHash,
+		&client.RedirectURIs,
+		&client.Public,
+		&client.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.OIDCClient{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get oidc client", "error", err, "client_id", id)
+		return domain.OIDCClient{}, fmt.Errorf("failed to get oidc client: %w", err)
+	}
+
+	return client, nil
+}
+
+func (r *OIDCRepository) ListClients(ctx context.Context) ([]domain.OIDCClient, error) {
+	query, args, err := r.goqu.From("oidc_clients").
+		Select("id", "name", "secret_hash", "redirect_uris", "public", "created_at").
+		Order(goqu.C("created_at").Desc()).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select clients query", "error", err)
+		return nil, fmt.Errorf("failed to build select clients query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get oidc clients", "error", err)
+		return nil, fmt.Errorf("failed to get oidc clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []domain.OIDCClient
+	for rows.Next() {
+		var client domain.OIDCClient
+		if err := rows.Scan(
+			&client.ID,
+			&client.Name,
+			&client.SecretHash,
+			&client.RedirectURIs,
+			&client.Public,
+			&client.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan oidc client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return clients, nil
+}
+
+func (r *OIDCRepository) DeleteClient(ctx context.Context, id string) error {
+	query, args, err := r.goqu.Delete("oidc_clients").
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete client query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete oidc client", "error", err, "client_id", id)
+		return fmt.Errorf("failed to delete oidc client: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *OIDCRepository) SaveCode(ctx context.Context, code domain.OIDCAuthorizationCode) error {
+	query, args, err := r.goqu.Insert("oidc_authorization_codes").
+		Rows(goqu.Record{
+			"code_hash":      code.CodeHash,
+			"client_id":      code.ClientID,
+			"user_id":        code.UserID,
+			"redirect_uri":   code.RedirectURI,
+			"scope":          code.Scope,
+			"nonce":          code.Nonce,
+			"code_challenge": code.CodeChallenge,
+			"auth_time":      code.AuthTime,
+			"expires_at":     code.ExpiresAt,
+		}).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert code query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to save authorization code", "error", err, "client_id", code.ClientID)
+		return fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeCode marks an unexpired authorization code as used and returns it.
+// A code can only be consumed once; later attempts return domain.ErrNotFound.
+func (r *OIDCRepository) ConsumeCode(ctx context.Context, codeHash string) (domain.OIDCAuthorizationCode, error) {
+	now := time.Now()
+
+	query, args, err := r.goqu.Update("oidc_authorization_codes").
+		Set(goqu.Record{"used_at": now}).
+		Where(
+			goqu.C("code_hash").Eq(codeHash),
+			goqu.C("used_at").IsNull(),
+			goqu.C("expires_at").Gt(now),
+		).
+		Returning("code_hash", "client_id", "user_id", "redirect_uri", "scope", "nonce", "code_challenge", "auth_time", "expires_at").
+		ToSQL()
+
+	if err != nil {
+		return domain.OIDCAuthorizationCode{}, fmt.Errorf("failed to build consume code query: %w", err)
+	}
+
+	var code domain.OIDCAuthorizationCode
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.Scope,
+		&code.Nonce,
+		&code.CodeChallenge,
+		&code.AuthTime,
+		&code.ExpiresAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.OIDCAuthorizationCode{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to consume authorization code", "error", err)
+		return domain.OIDCAuthorizationCode{}, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+// ownedEmail matches the addresses that belong to their user: the primary
+// address and verified secondary addresses. Unverified secondary addresses
+// are only claims and own nothing.
+var ownedEmail = goqu.Or(goqu.C("is_primary").IsTrue(), goqu.C("verified_at").IsNotNull())
+
+// emailOwner selects the ID of the user owning email. Addresses are compared
+// case-insensitively.
+func (r *UserRepository) emailOwner(email string) *goqu.SelectDataset {
+	return r.goqu.From("user_emails").
+		Select("user_id").
+		Where(goqu.Func("LOWER", goqu.C("email")).Eq(strings.ToLower(email)), ownedEmail)
+}
+
+// releaseEmailClaims removes the unverified claims of other users on the
+// addresses userID owns, once the addresses were created or verified.
+func (r *UserRepository) releaseEmailClaims(ctx context.Context, tx pgx.Tx, userID string) error {
+	owned := r.goqu.From("user_emails").
+		Select(goqu.Func("LOWER", goqu.C("email"))).
+		Where(goqu.C("user_id").Eq(userID), ownedEmail)
+
+	query, args, err := r.goqu.Delete("user_emails").
+		Where(
+			goqu.C("user_id").Neq(userID),
+			goqu.C("is_primary").IsFalse(),
+			goqu.C("verified_at").IsNull(),
+			goqu.Func("LOWER", goqu.C("email")).In(owned),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build release email claims query: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to release email claims", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to release email claims: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		slog.InfoContext(ctx, "released unverified email claims", "user_id", userID, "count", result.RowsAffected())
+	}
+	return nil
+}
+
+func (r *UserRepository) ListEmails(ctx context.Context, userID string) ([]domain.UserEmail, error) {
+	query, args, err := r.goqu.From("user_emails").
+		Select("email", "is_primary", "verified_at", "created_at").
+		Where(goqu.C("user_id").Eq(userID)).
+		Order(goqu.C("is_primary").Desc(), goqu.C("created_at").Asc()).
+		ToSQL()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select emails query: %w", err)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get user emails", "error", err, "user_id", userID)
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+	defer rows.Close()
+
+	var emails []domain.UserEmail
+	for rows.Next() {
+		var email domain.UserEmail
+		if err := rows.Scan(&email.Email, &email.Primary, &email.VerifiedAt, &email.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user email: %w", err)
+		}
+		emails = append(emails, email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return emails, nil
+}
+
+// AddEmail adds an unverified secondary address. It returns
+// domain.ErrConflict if the user already has the address.
+func (r *UserRepository) AddEmail(ctx context.Context, userID, email string) error {
+	query, args, err := r.goqu.Insert("user_emails").
+		Rows(goqu.Record{
+			"user_id":    userID,
+			"email":      email,
+			"is_primary": false,
+			"created_at": time.Now(),
+		}).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert email query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to add email: %w", domain.ErrConflict)
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to add user email", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to add user email: %w", err)
+	}
+
+	slog.InfoContext(ctx, "user email added", "user_id", userID)
+	return nil
+}
+
+// RemoveEmail removes a secondary address. The primary address cannot be
+// removed.
+func (r *UserRepository) RemoveEmail(ctx context.Context, userID, email string) error {
+	query, args, err := r.goqu.Delete("user_emails").
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("email").Eq(email),
+			goqu.C("is_primary").IsFalse(),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build delete email query: %w", err)
+	}
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to remove user email", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to remove user email: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	slog.InfoContext(ctx, "user email removed", "user_id", userID)
+	return nil
+}
+
+// PromoteEmail makes a verified secondary address the primary one and the
+// user's email. It returns domain.ErrNotFound if the user has no such
+// verified address.
+func (r *UserRepository) PromoteEmail(ctx context.Context, userID, email string) error {
+	now := time.Now()
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query, args, err := r.goqu.Update("user_emails").
+		Set(goqu.Record{"is_primary": false}).
+		Where(goqu.C("user_id").Eq(userID), goqu.C("is_primary").IsTrue()).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build demote email query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to demote primary email: %w", err)
+	}
+
+	query, args, err = r.goqu.Update("user_emails").
+		Set(goqu.Record{"is_primary": true}).
+		Where(
+			goqu.C("user_id").Eq(userID),
+			goqu.C("email").Eq(email),
+			goqu.C("verified_at").IsNotNull(),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build promote email query: %w", err)
+	}
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to promote email: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	query, args, err = r.goqu.Update("users").
+		Set(goqu.Record{
+			"email":             email,
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).
+		Where(goqu.C("id").Eq(userID)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build update primary email query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to promote email: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to update primary email", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to update primary email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "user email promoted", "user_id", userID)
+	return nil
+}
+
+// syncPrimaryEmail copies the user's email and its verification state to the
+// primary address after the email was changed on the user.
+func (r *UserRepository) syncPrimaryEmail(ctx context.Context, tx pgx.Tx, userID string) error {
+	query, args, err := r.goqu.Update(goqu.T("user_emails").As("e")).
+		Set(goqu.Record{
+			"email":       goqu.I("u.email"),
+			"verified_at": goqu.I("u.email_verified_at"),
+		}).
+		From(goqu.T("users").As("u")).
+		Where(
+			goqu.I("u.id").Eq(goqu.I("e.user_id")),
+			goqu.I("e.user_id").Eq(userID),
+			goqu.I("e.is_primary").IsTrue(),
+			goqu.I("e.email").Neq(goqu.I("u.email")),
+		).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build sync primary email query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("failed to update primary email: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to sync primary email", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to sync primary email: %w", err)
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -13,6 +14,40 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+var userColumns = []interface{}{
+	"id", "name", "email", "role", "locked_until",
+	goqu.COALESCE(goqu.C("username"), "").As("username"),
+	goqu.COALESCE(goqu.C("username_key"), "").As("username_key"),
+	"email_verified_at", "pending_email",
+	"avatar_version", "avatar_urls",
+	"last_login_at", "last_seen_at",
+	"status", "status_reason", "status_changed_at",
+	"created_at", "updated_at",
+}
+
+func userScanTargets(user *domain.User) []interface{} {
+	return []interface{}{
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.LockedUntil,
+		&user.Username,
+		&user.UsernameKey,
+		&user.EmailVerifiedAt,
+		&user.PendingEmail,
+		&user.AvatarVersion,
+		&user.AvatarURLs,
+		&user.LastLoginAt,
+		&user.LastSeenAt,
+		&user.Status,
+		&user.StatusReason,
+		&user.StatusChangedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	}
+}
+
 type UserRepository struct {
 	db   *database.DB
 	goqu *goqu.Database
@@ -33,79 +68,160 @@ func (r *UserRepository) Create(ctx context.Context, user domain.User) error {
 	user.UpdatedAt = now
 
 	query, args, err := r.goqu.Insert("users").
-		Cols("id", "name", "email", "role", "created_at", "updated_at").
-		Vals(goqu.Vals{user.ID, user.Name, user.Email, user.Role, user.CreatedAt, user.UpdatedAt}).
+		Cols("id", "name", "email", "role", "status", "password_hash", "email_verified_at", "username", "username_key", "created_at", "updated_at").
+		Vals(goqu.Vals{
+			user.ID, user.Name, user.Email, user.Role, user.Status, user.PasswordHash, user.EmailVerifiedAt,
+			nullIfEmpty(user.Username), nullIfEmpty(user.UsernameKey), user.CreatedAt, user.UpdatedAt,
+		}).
 		ToSQL()
 
 	if err != nil {
-		slog.Error("failed to build insert query", "error", err)
+		slog.ErrorContext(ctx, "failed to build insert query", "error", err)
 		return fmt.Errorf("failed to build insert query: %w", err)
 	}
 
-	slog.Debug("executing insert query", "query", query, "args", args)
+	slog.DebugContext(ctx, "executing insert query", "query", query, "args", args)
 
-	_, err = r.db.Pool.Exec(ctx, query, args...)
+	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
-		slog.Error("failed to create user", "error", err, "user_id", user.ID)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	_, err = tx.Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			slog.WarnContext(ctx, "user already exists", "user_id", user.ID)
+			return fmt.Errorf("failed to create user: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to create user", "error", err, "user_id", user.ID)
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 
-	slog.Info("user created successfully", "user_id", user.ID, "email", user.Email, "created_at", user.CreatedAt)
+	query, args, err = r.goqu.Insert("user_emails").
+		Rows(goqu.Record{
+			"user_id":     user.ID,
+			"email":       user.Email,
+			"is_primary":  true,
+			"verified_at": user.EmailVerifiedAt,
+			"created_at":  now,
+		}).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build insert email query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		if isUniqueViolation(err) {
+			slog.WarnContext(ctx, "user email already in use", "user_id", user.ID)
+			return fmt.Errorf("failed to create user: %w", domain.ErrConflict)
+		}
+		return fmt.Errorf("failed to create user email: %w", err)
+	}
+
+	if err := r.releaseEmailClaims(ctx, tx, user.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "user created successfully", "user_id", user.ID, "email", user.Email, "created_at", user.CreatedAt)
 	return nil
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id string) (domain.User, error) {
 	query, args, err := r.goqu.From("users").
-		Select("id", "name", "email", "role", "created_at", "updated_at").
+		Select(userColumns...).
 		Where(goqu.C("id").Eq(id)).
 		ToSQL()
 
 	if err != nil {
-		slog.Error("failed to build select query", "error", err)
+		slog.ErrorContext(ctx, "failed to build select query", "error", err)
 		return domain.User{}, fmt.Errorf("failed to build select query: %w", err)
 	}
 
-	slog.Debug("executing select query", "query", query, "args", args)
+	slog.DebugContext(ctx, "executing select query", "query", query, "args", args)
 
 	var user domain.User
-	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(userScanTargets(&user)...)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			slog.Warn("user not found", "user_id", id)
+			slog.WarnContext(ctx, "user not found", "user_id", id)
 			return domain.User{}, domain.ErrNotFound
 		}
-		slog.Error("failed to get user", "error", err, "user_id", id)
+		slog.ErrorContext(ctx, "failed to get user", "error", err, "user_id", id)
 		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	slog.Info("user retrieved successfully", "user_id", id)
+	slog.InfoContext(ctx, "user retrieved successfully", "user_id", id)
 	return user, nil
 }
 
-func (r *UserRepository) GetAll(ctx context.Context) ([]domain.User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (domain.User, error) {
 	query, args, err := r.goqu.From("users").
-		Select("id", "name", "email", "role", "created_at", "updated_at").
-		Order(goqu.C("created_at").Desc()).
+		Select(append(userColumns, "password_hash")...).
+		Where(goqu.C("id").In(r.emailOwner(email)), goqu.C("status").Neq(domain.StatusDeleted)).
 		ToSQL()
 
 	if err != nil {
-		slog.Error("failed to build select all query", "error", err)
+		slog.ErrorContext(ctx, "failed to build select by email query", "error", err)
+		return domain.User{}, fmt.Errorf("failed to build select by email query: %w", err)
+	}
+
+	slog.DebugContext(ctx, "executing select by email query", "query", query, "args", args)
+
+	var user domain.User
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(append(userScanTargets(&user), &user.PasswordHash)...)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			slog.WarnContext(ctx, "user not found by email")
+			return domain.User{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get user by email", "error", err)
+		return domain.User{}, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	ds := r.goqu.From("users").
+		Select(userColumns...).
+		Where(goqu.C("status").Neq(domain.StatusDeleted)).
+		Order(goqu.C("created_at").Desc(), goqu.C("id").Desc())
+
+	if filter.InactiveSince != nil {
+		ds = ds.Where(lastActiveAt.Lt(*filter.InactiveSince))
+	}
+	if len(filter.IDs) > 0 {
+		ds = ds.Where(goqu.C("id").In(filter.IDs))
+	}
+	if filter.Limit > 0 {
+		ds = ds.Limit(uint(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		ds = ds.Offset(uint(filter.Offset))
+	}
+
+	query, args, err := ds.ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select all query", "error", err)
 		return nil, fmt.Errorf("failed to build select all query: %w", err)
 	}
 
-	slog.Debug("executing select all query", "query", query, "args", args)
+	slog.DebugContext(ctx, "executing select all query", "query", query, "args", args)
 
 	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
-		slog.Error("failed to get users", "error", err)
+		slog.ErrorContext(ctx, "failed to get users", "error", err)
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
 	defer rows.Close()
@@ -113,27 +229,20 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]domain.User, error) {
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		err := rows.Scan(
-			&user.ID,
-			&user.Name,
-			&user.Email,
-			&user.Role,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		err := rows.Scan(userScanTargets(&user)...)
 		if err != nil {
-			slog.Error("failed to scan user", "error", err)
+			slog.ErrorContext(ctx, "failed to scan user", "error", err)
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
 		users = append(users, user)
 	}
 
 	if err := rows.Err(); err != nil {
-		slog.Error("error during rows iteration", "error", err)
+		slog.ErrorContext(ctx, "error during rows iteration", "error", err)
 		return nil, fmt.Errorf("error during rows iteration: %w", err)
 	}
 
-	slog.Info("users retrieved successfully", "count", len(users))
+	slog.InfoContext(ctx, "users retrieved successfully", "count", len(users))
 	return users, nil
 }
 
@@ -142,59 +251,294 @@ func (r *UserRepository) Update(ctx context.Context, id string, user domain.User
 
 	query, args, err := r.goqu.Update("users").
 		Set(goqu.Record{
-			"name":       user.Name,
-			"email":      user.Email,
-			"role":       user.Role,
-			"updated_at": user.UpdatedAt,
+			"name":              user.Name,
+			"email":             user.Email,
+			"role":              user.Role,
+			"email_verified_at": user.EmailVerifiedAt,
+			"pending_email":     user.PendingEmail,
+			"username":          nullIfEmpty(user.Username),
+			"username_key":      nullIfEmpty(user.UsernameKey),
+			"updated_at":        user.UpdatedAt,
 		}).
 		Where(goqu.C("id").Eq(id)).
 		ToSQL()
 
 	if err != nil {
-		slog.Error("failed to build update query", "error", err)
+		slog.ErrorContext(ctx, "failed to build update query", "error", err)
 		return fmt.Errorf("failed to build update query: %w", err)
 	}
 
-	slog.Debug("executing update query", "query", query, "args", args)
+	slog.DebugContext(ctx, "executing update query", "query", query, "args", args)
 
-	result, err := r.db.Pool.Exec(ctx, query, args...)
+	tx, err := r.db.Pool.Begin(ctx)
 	if err != nil {
-		slog.Error("failed to update user", "error", err, "user_id", id)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			slog.WarnContext(ctx, "user update conflicts with existing user", "user_id", id)
+			return fmt.Errorf("failed to update user: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to update user", "error", err, "user_id", id)
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		slog.Warn("user not found for update", "user_id", id)
+		slog.WarnContext(ctx, "user not found for update", "user_id", id)
 		return domain.ErrNotFound
 	}
 
-	slog.Info("user updated successfully", "user_id", id, "updated_at", user.UpdatedAt)
+	if err := r.syncPrimaryEmail(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := r.releaseEmailClaims(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.InfoContext(ctx, "user updated successfully", "user_id", id, "updated_at", user.UpdatedAt)
 	return nil
 }
 
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
-	query, args, err := r.goqu.Delete("users").
+// MarkEmailVerified marks one of the user's addresses as verified. When it is
+// the primary address, the user's email is marked verified as well.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id, email string) error {
+	now := time.Now()
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query, args, err := r.goqu.Update("user_emails").
+		Set(goqu.Record{"verified_at": now}).
+		Where(goqu.C("user_id").Eq(id), goqu.C("email").Eq(email)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build verify email query", "error", err)
+		return fmt.Errorf("failed to build verify email query: %w", err)
+	}
+
+	slog.DebugContext(ctx, "executing verify email query", "query", query, "args", args)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			slog.WarnContext(ctx, "verified email is owned by another user", "user_id", id)
+			return fmt.Errorf("failed to verify email: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to verify email", "error", err, "user_id", id)
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	query, args, err = r.goqu.Update("users").
+		Set(goqu.Record{
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).
+		Where(goqu.C("id").Eq(id), goqu.C("email").Eq(email)).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build verify primary email query: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to verify primary email", "error", err, "user_id", id)
+		return fmt.Errorf("failed to verify primary email: %w", err)
+	}
+
+	if err := r.releaseEmailClaims(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange replaces the user's email with the pending email if the
+// pending email is still email, and marks it verified.
+func (r *UserRepository) ConfirmEmailChange(ctx context.Context, id, email string) error {
+	now := time.Now()
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	query, args, err := r.goqu.Update("users").
+		Set(goqu.Record{
+			"email":             goqu.I("pending_email"),
+			"pending_email":     "",
+			"email_verified_at": now,
+			"updated_at":        now,
+		}).
+		Where(goqu.C("id").Eq(id), goqu.C("pending_email").Eq(email)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build confirm email change query", "error", err)
+		return fmt.Errorf("failed to build confirm email change query: %w", err)
+	}
+
+	slog.DebugContext(ctx, "executing confirm email change query", "query", query, "args", args)
+
+	result, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			slog.WarnContext(ctx, "email change conflicts with existing user", "user_id", id)
+			return fmt.Errorf("failed to confirm email change: %w", domain.ErrConflict)
+		}
+		slog.ErrorContext(ctx, "failed to confirm email change", "error", err, "user_id", id)
+		return fmt.Errorf("failed to confirm email change: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	if err := r.syncPrimaryEmail(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := r.releaseEmailClaims(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetAvatar records the current avatar version of a user and the URLs of its
+// variants. An empty version removes the avatar.
+func (r *UserRepository) SetAvatar(ctx context.Context, id, version string, urls map[string]string) error {
+	if urls == nil {
+		urls = map[string]string{}
+	}
+	encoded, err := json.Marshal(urls)
+	if err != nil {
+		return fmt.Errorf("failed to encode avatar urls: %w", err)
+	}
+
+	query, args, err := r.goqu.Update("users").
+		Set(goqu.Record{
+			"avatar_version": version,
+			"avatar_urls":    string(encoded),
+			"updated_at":     time.Now(),
+		}).
 		Where(goqu.C("id").Eq(id)).
 		ToSQL()
 
 	if err != nil {
-		slog.Error("failed to build delete query", "error", err)
-		return fmt.Errorf("failed to build delete query: %w", err)
+		slog.ErrorContext(ctx, "failed to build avatar query", "error", err)
+		return fmt.Errorf("failed to build avatar query: %w", err)
 	}
 
-	slog.Debug("executing delete query", "query", query, "args", args)
+	slog.DebugContext(ctx, "executing avatar query", "query", query, "args", args)
 
 	result, err := r.db.Pool.Exec(ctx, query, args...)
 	if err != nil {
-		slog.Error("failed to delete user", "error", err, "user_id", id)
-		return fmt.Errorf("failed to delete user: %w", err)
+		slog.ErrorContext(ctx, "failed to set avatar", "error", err, "user_id", id)
+		return fmt.Errorf("failed to set avatar: %w", err)
 	}
 
 	if result.RowsAffected() == 0 {
-		slog.Warn("user not found for deletion", "user_id", id)
 		return domain.ErrNotFound
 	}
 
-	slog.Info("user deleted successfully", "user_id", id)
+	return nil
+}
+
+func (r *UserRepository) SetLockedUntil(ctx context.Context, id string, lockedUntil *time.Time) error {
+	query, args, err := r.goqu.Update("users").
+		Set(goqu.Record{
+			"locked_until": lockedUntil,
+			"updated_at":   time.Now(),
+		}).
+		Where(goqu.C("id").Eq(id)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build lock query", "error", err)
+		return fmt.Errorf("failed to build lock query: %w", err)
+	}
+
+	slog.DebugContext(ctx, "executing lock query", "query", query, "args", args)
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to set locked_until", "error", err, "user_id", id)
+		return fmt.Errorf("failed to set locked_until: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateStatus moves a user from one status to another. The update only
+// applies while the user is still in the expected status, so concurrent
+// transitions cannot both succeed; domain.ErrInvalidTransition is returned in
+// that case.
+func (r *UserRepository) UpdateStatus(ctx context.Context, id, from, to, reason string) error {
+	now := time.Now()
+
+	query, args, err := r.goqu.Update("users").
+		Set(goqu.Record{
+			"status":            to,
+			"status_reason":     reason,
+			"status_changed_at": now,
+			"updated_at":        now,
+		}).
+		Where(goqu.C("id").Eq(id), goqu.C("status").Eq(from)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build update status query", "error", err)
+		return fmt.Errorf("failed to build update status query: %w", err)
+	}
+
+	slog.DebugContext(ctx, "executing update status query", "query", query, "args", args)
+
+	result, err := r.db.Pool.Exec(ctx, query, args...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update user status", "error", err, "user_id", id)
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		slog.WarnContext(ctx, "user status changed concurrently", "user_id", id, "from", from, "to", to)
+		return domain.ErrInvalidTransition
+	}
+
+	slog.InfoContext(ctx, "user status updated", "user_id", id, "from", from, "to", to)
 	return nil
 }
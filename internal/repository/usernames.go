@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *UserRepository) GetByUsernameKey(ctx context.Context, key string) (domain.User, error) {
+	query, args, err := r.goqu.From("users").
+		Select(userColumns...).
+		Where(goqu.C("username_key").Eq(key)).
+		ToSQL()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build select by username query", "error", err)
+		return domain.User{}, fmt.Errorf("failed to build select by username query: %w", err)
+	}
+
+	var user domain.User
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(userScanTargets(&user)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get user by username", "error", err)
+		return domain.User{}, fmt.Errorf("failed to get user by username: %w", err)
+	}
+
+	return user, nil
+}
+
+// HoldUsername records a released username so that only its previous owner
+// can claim it before hold.AvailableAt.
+func (r *UserRepository) HoldUsername(ctx context.Context, hold domain.UsernameHold) error {
+	now := time.Now()
+
+	query, args, err := r.goqu.Insert("released_usernames").
+		Rows(goqu.Record{
+			"username_key": hold.Key,
+			"user_id":      hold.UserID,
+			"released_at":  now,
+			"available_at": hold.AvailableAt,
+		}).
+		OnConflict(goqu.DoUpdate("username_key", goqu.Record{
+			"user_id":      hold.UserID,
+			"released_at":  now,
+			"available_at": hold.AvailableAt,
+		})).
+		ToSQL()
+
+	if err != nil {
+		return fmt.Errorf("failed to build hold username query: %w", err)
+	}
+
+	if _, err := r.db.Pool.Exec(ctx, query, args...); err != nil {
+		slog.ErrorContext(ctx, "failed to hold username", "error", err, "user_id", hold.UserID)
+		return fmt.Errorf("failed to hold username: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsernameHold returns the active hold on a username key, or
+// domain.ErrNotFound if it can be claimed freely.
+func (r *UserRepository) GetUsernameHold(ctx context.Context, key string) (domain.UsernameHold, error) {
+	query, args, err := r.goqu.From("released_usernames").
+		Select("username_key", "user_id", "available_at").
+		Where(
+			goqu.C("username_key").Eq(key),
+			goqu.C("available_at").Gt(time.Now()),
+		).
+		ToSQL()
+
+	if err != nil {
+		return domain.UsernameHold{}, fmt.Errorf("failed to build select username hold query: %w", err)
+	}
+
+	var hold domain.UsernameHold
+	err = r.db.Pool.QueryRow(ctx, query, args...).Scan(&hold.Key, &hold.UserID, &hold.AvailableAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.UsernameHold{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to get username hold", "error", err)
+		return domain.UsernameHold{}, fmt.Errorf("failed to get username hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
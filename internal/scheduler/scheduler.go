@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs job at the given interval until ctx is cancelled. Errors are
+// logged and do not stop the schedule.
+func Every(ctx context.Context, name string, interval time.Duration, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		slog.WarnContext(ctx, "scheduled job disabled", "job", name)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	slog.InfoContext(ctx, "scheduled job started", "job", name, "interval", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "scheduled job stopped", "job", name)
+			return
+		case <-ticker.C:
+			start := time.Now()
+			if err := job(ctx); err != nil {
+				slog.ErrorContext(ctx, "scheduled job failed", "job", name, "error", err)
+				continue
+			}
+			slog.DebugContext(ctx, "scheduled job finished", "job", name, "duration_ms", time.Since(start).Milliseconds())
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package scim
+
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+type supported struct {
+	Supported bool `json:"supported"`
+}
+
+type bulk struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type filterSupport struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type authenticationScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Primary     bool   `json:"primary"`
+}
+
+type ServiceProviderConfig struct {
+	Schemas               []string               `json:"schemas"`
+	Patch                 supported              `json:"patch"`
+	Bulk                  bulk                   `json:"bulk"`
+	Filter                filterSupport          `json:"filter"`
+	ChangePassword        supported              `json:"changePassword"`
+	Sort                  supported              `json:"sort"`
+	ETag                  supported              `json:"etag"`
+	AuthenticationSchemes []authenticationScheme `json:"authenticationSchemes"`
+	Meta                  Meta                   `json:"meta"`
+}
+
+func NewServiceProviderConfig(baseURL string) ServiceProviderConfig {
+	return ServiceProviderConfig{
+		Schemas: []string{SchemaServiceProviderConfig},
+		Patch:   supported{Supported: true},
+		Bulk:    bulk{Supported: false},
+		Filter:  filterSupport{Supported: true, MaxResults: MaxPageSize},
+		ETag:    supported{Supported: true},
+		AuthenticationSchemes: []authenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Authentication with a bearer token",
+			Primary:     true,
+		}},
+		Meta: Meta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     baseURL + "/ServiceProviderConfig",
+		},
+	}
+}
+
+type Attribute struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	MultiValued   bool        `json:"multiValued"`
+	Required      bool        `json:"required"`
+	CaseExact     bool        `json:"caseExact"`
+	Mutability    string      `json:"mutability"`
+	Returned      string      `json:"returned"`
+	Uniqueness    string      `json:"uniqueness"`
+	SubAttributes []Attribute `json:"subAttributes,omitempty"`
+}
+
+type Schema struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Attributes  []Attribute `json:"attributes"`
+	Meta        Meta        `json:"meta"`
+}
+
+type ResourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+	Meta        Meta     `json:"meta"`
+}
+
+func attr(name, typ string, required bool, mutability string) Attribute {
+	return Attribute{
+		Name:       name,
+		Type:       typ,
+		Required:   required,
+		Mutability: mutability,
+		Returned:   "default",
+		Uniqueness: "none",
+	}
+}
+
+func multiValued(name string, mutability string, subAttributes ...Attribute) Attribute {
+	a := attr(name, "complex", false, mutability)
+	a.MultiValued = true
+	a.SubAttributes = subAttributes
+	return a
+}
+
+func Schemas(baseURL string) []Schema {
+	userName := attr("userName", "string", true, "readWrite")
+	userName.Uniqueness = "server"
+
+	displayName := attr("displayName", "string", true, "readWrite")
+	displayName.Uniqueness = "server"
+
+	return []Schema{
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaUser,
+			Name:        "User",
+			Description: "User Account",
+			Attributes: []Attribute{
+				userName,
+				{
+					Name: "name", Type: "complex", Mutability: "readWrite", Returned: "default", Uniqueness: "none",
+					SubAttributes: []Attribute{
+						attr("formatted", "string", false, "readWrite"),
+						attr("givenName", "string", false, "readWrite"),
+						attr("familyName", "string", false, "readWrite"),
+					},
+				},
+				attr("displayName", "string", false, "readWrite"),
+				attr("active", "boolean", false, "readWrite"),
+				multiValued("emails", "readWrite",
+					attr("value", "string", true, "readWrite"),
+					attr("type", "string", false, "readWrite"),
+					attr("primary", "boolean", false, "readWrite"),
+				),
+				multiValued("roles", "readWrite",
+					attr("value", "string", true, "readWrite"),
+					attr("primary", "boolean", false, "readWrite"),
+				),
+				multiValued("groups", "readOnly",
+					attr("value", "string", false, "readOnly"),
+					attr("display", "string", false, "readOnly"),
+					attr("$ref", "reference", false, "readOnly"),
+				),
+			},
+			Meta: Meta{ResourceType: "Schema", Location: baseURL + "/Schemas/" + SchemaUser},
+		},
+		{
+			Schemas:     []string{SchemaSchema},
+			ID:          SchemaGroup,
+			Name:        "Group",
+			Description: "Group",
+			Attributes: []Attribute{
+				displayName,
+				multiValued("members", "readWrite",
+					attr("value", "string", false, "immutable"),
+					attr("display", "string", false, "readOnly"),
+					attr("$ref", "reference", false, "immutable"),
+					attr("type", "string", false, "immutable"),
+				),
+			},
+			Meta: Meta{ResourceType: "Schema", Location: baseURL + "/Schemas/" + SchemaGroup},
+		},
+	}
+}
+
+func ResourceTypes(baseURL string) []ResourceType {
+	return []ResourceType{
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          "User",
+			Name:        "User",
+			Endpoint:    "/Users",
+			Description: "User Account",
+			Schema:      SchemaUser,
+			Meta:        Meta{ResourceType: "ResourceType", Location: baseURL + "/ResourceTypes/User"},
+		},
+		{
+			Schemas:     []string{SchemaResourceType},
+			ID:          "Group",
+			Name:        "Group",
+			Endpoint:    "/Groups",
+			Description: "Group",
+			Schema:      SchemaGroup,
+			Meta:        Meta{ResourceType: "ResourceType", Location: baseURL + "/ResourceTypes/Group"},
+		},
+	}
+}
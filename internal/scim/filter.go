@@ -0,0 +1,482 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed SCIM filter expression (RFC 7644 section 3.4.2.2). It is
+// evaluated against the JSON representation of a resource decoded into a
+// generic map.
+type Filter interface {
+	Match(resource map[string]interface{}) bool
+}
+
+type attrExpr struct {
+	path  attrPath
+	op    string
+	value interface{}
+}
+
+type logicalExpr struct {
+	op          string
+	left, right Filter
+}
+
+type notExpr struct {
+	filter Filter
+}
+
+type valuePathExpr struct {
+	attr   string
+	filter Filter
+}
+
+type attrPath struct {
+	attr    string
+	subAttr string
+}
+
+// ParseFilter parses a SCIM filter such as
+// `userName eq "bjensen" and emails[type eq "work" and value co "@example.com"]`.
+func ParseFilter(input string) (Filter, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, badRequest(ScimTypeInvalidFilter, "unexpected token %q", p.peek().text)
+	}
+
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(input); end++ {
+				if input[end] == '\\' {
+					end++
+					continue
+				}
+				if input[end] == '"' {
+					break
+				}
+			}
+			if end >= len(input) {
+				return nil, badRequest(ScimTypeInvalidFilter, "unterminated string")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(input[i:end+1]), &s); err != nil {
+				return nil, badRequest(ScimTypeInvalidFilter, "invalid string %s", input[i:end+1])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s})
+			i = end + 1
+		default:
+			end := i
+			for end < len(input) && !strings.ContainsRune(" \t()[]\"", rune(input[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[i:end]})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	if t := p.next(); t.kind != kind {
+		return badRequest(ScimTypeInvalidFilter, "expected %q", text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{op: "or", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{op: "and", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		if err := p.expect(tokenLParen, "("); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return notExpr{filter: inner}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRParen, ")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+
+	return p.parseAttrExpr()
+}
+
+func (p *filterParser) parseAttrExpr() (Filter, error) {
+	t := p.next()
+	if t.kind != tokenWord {
+		return nil, badRequest(ScimTypeInvalidFilter, "expected attribute path")
+	}
+
+	if p.peek().kind == tokenLBracket {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathExpr{attr: parseAttrPath(t.text).attr, filter: inner}, nil
+	}
+
+	path := parseAttrPath(t.text)
+
+	opToken := p.next()
+	if opToken.kind != tokenWord {
+		return nil, badRequest(ScimTypeInvalidFilter, "expected operator after %q", t.text)
+	}
+	op := strings.ToLower(opToken.text)
+
+	switch op {
+	case "pr":
+		return attrExpr{path: path, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, badRequest(ScimTypeInvalidFilter, "unknown operator %q", opToken.text)
+	}
+
+	valueToken := p.next()
+	var value interface{}
+	switch valueToken.kind {
+	case tokenString:
+		value = valueToken.text
+	case tokenWord:
+		switch strings.ToLower(valueToken.text) {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			n, err := strconv.ParseFloat(valueToken.text, 64)
+			if err != nil {
+				return nil, badRequest(ScimTypeInvalidFilter, "invalid value %q", valueToken.text)
+			}
+			value = n
+		}
+	default:
+		return nil, badRequest(ScimTypeInvalidFilter, "expected value after %q", opToken.text)
+	}
+
+	return attrExpr{path: path, op: op, value: value}, nil
+}
+
+// parseAttrPath strips a schema URN prefix and splits off a sub-attribute,
+// e.g. "urn:ietf:params:scim:schemas:core:2.0:User:name.givenName".
+func parseAttrPath(raw string) attrPath {
+	if strings.HasPrefix(strings.ToLower(raw), "urn:") {
+		if i := strings.LastIndex(raw, ":"); i >= 0 {
+			raw = raw[i+1:]
+		}
+	}
+
+	attr, subAttr, _ := strings.Cut(raw, ".")
+	return attrPath{attr: attr, subAttr: subAttr}
+}
+
+func (e logicalExpr) Match(resource map[string]interface{}) bool {
+	if e.op == "and" {
+		return e.left.Match(resource) && e.right.Match(resource)
+	}
+	return e.left.Match(resource) || e.right.Match(resource)
+}
+
+func (e notExpr) Match(resource map[string]interface{}) bool {
+	return !e.filter.Match(resource)
+}
+
+func (e valuePathExpr) Match(resource map[string]interface{}) bool {
+	values, _ := lookup(resource, e.attr).([]interface{})
+	for _, v := range values {
+		if element, ok := v.(map[string]interface{}); ok && e.filter.Match(element) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e attrExpr) Match(resource map[string]interface{}) bool {
+	values := resolve(resource, e.path)
+
+	if e.op == "pr" {
+		for _, v := range values {
+			if present(v) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(values) == 0 {
+		return e.op == "ne" && e.value != nil || e.op == "eq" && e.value == nil
+	}
+
+	for _, v := range values {
+		if compare(v, e.op, e.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns every value addressed by path. For multi-valued attributes
+// the sub-attribute (or "value" when none is given) of each element is used.
+func resolve(resource map[string]interface{}, path attrPath) []interface{} {
+	v := lookup(resource, path.attr)
+
+	if list, ok := v.([]interface{}); ok {
+		sub := path.subAttr
+		if sub == "" {
+			sub = "value"
+		}
+		var out []interface{}
+		for _, item := range list {
+			if element, ok := item.(map[string]interface{}); ok {
+				out = append(out, lookup(element, sub))
+			} else {
+				out = append(out, item)
+			}
+		}
+		return out
+	}
+
+	if path.subAttr != "" {
+		complex, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = lookup(complex, path.subAttr)
+	}
+
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+// lookup finds an attribute by name. SCIM attribute names are case
+// insensitive.
+func lookup(m map[string]interface{}, name string) interface{} {
+	if v, ok := m[name]; ok {
+		return v
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func present(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return false
+	case string:
+		return value != ""
+	case []interface{}:
+		return len(value) > 0
+	case map[string]interface{}:
+		return len(value) > 0
+	default:
+		return true
+	}
+}
+
+func compare(actual interface{}, op string, expected interface{}) bool {
+	switch want := expected.(type) {
+	case string:
+		got, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		got, want = strings.ToLower(got), strings.ToLower(want)
+		switch op {
+		case "eq":
+			return got == want
+		case "ne":
+			return got != want
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case float64:
+		got, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "ne":
+			return got != want
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case bool:
+		got, ok := actual.(bool)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "ne":
+			return got != want
+		}
+	case nil:
+		switch op {
+		case "eq":
+			return actual == nil
+		case "ne":
+			return actual != nil
+		}
+	}
+
+	return false
+}
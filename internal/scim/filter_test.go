@@ -0,0 +1,135 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const filterTestUser = `{
+	"userName": "bjensen",
+	"displayName": "Barbara Jensen",
+	"title": "",
+	"active": true,
+	"name": {"givenName": "Barbara", "familyName": "Jensen"},
+	"emails": [
+		{"value": "bjensen@example.com", "type": "work", "primary": true},
+		{"value": "babs@jensen.org", "type": "home"}
+	],
+	"meta": {"resourceType": "User", "version": 3}
+}`
+
+func TestParseFilterMatch(t *testing.T) {
+	var resource map[string]interface{}
+	if err := json.Unmarshal([]byte(filterTestUser), &resource); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{`userName eq "bjensen"`, true},
+		{`userName eq "BJensen"`, true},
+		{`USERNAME eq "bjensen"`, true},
+		{`userName eq "jsmith"`, false},
+		{`userName ne "jsmith"`, true},
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`meta.version eq 3`, true},
+		{`meta.version gt 2`, true},
+		{`meta.version lt 3`, false},
+		{`nickName eq null`, true},
+
+		{`displayName co "jen"`, true},
+		{`displayName co "smith"`, false},
+		{`emails co "example.com"`, true},
+		{`emails.value co "jensen.org"`, true},
+
+		{`userName sw "bj"`, true},
+		{`userName sw "jensen"`, false},
+		{`name.familyName sw "J"`, true},
+		{`userName ew "sen"`, true},
+
+		{`userName pr`, true},
+		{`nickName pr`, false},
+		{`title pr`, false},
+		{`emails pr`, true},
+		{`name.givenName pr`, true},
+
+		{`userName eq "bjensen" and active eq true`, true},
+		{`userName eq "bjensen" and active eq false`, false},
+		{`userName eq "jsmith" or active eq true`, true},
+		{`userName eq "jsmith" or active eq false`, false},
+		{`userName eq "jsmith" and active eq false or displayName co "Barbara"`, true},
+		{`userName eq "bjensen" and (active eq false or displayName co "Barbara")`, true},
+		{`userName eq "bjensen" AND active eq true`, true},
+
+		{`not (userName eq "jsmith")`, true},
+		{`not (userName eq "bjensen")`, false},
+		{`not (userName eq "jsmith" or active eq true)`, false},
+		{`userName pr and not (nickName pr)`, true},
+
+		{`emails[type eq "work" and value co "@example.com"]`, true},
+		{`emails[type eq "home" and value co "@example.com"]`, false},
+		{`emails[primary eq true]`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, true},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:name.familyName eq "jensen"`, true},
+		{`displayName eq "Barbara \"Babs\" Jensen"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseFilter: %v", err)
+			}
+			if got := filter.Match(resource); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterMalformed(t *testing.T) {
+	tests := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName xx "bjensen"`,
+		`userName eq bjensen`,
+		`userName eq "bjensen`,
+		`userName eq "bjensen" and`,
+		`userName eq "bjensen" or`,
+		`and userName eq "bjensen"`,
+		`userName eq "bjensen" userName eq "jsmith"`,
+		`(userName eq "bjensen"`,
+		`userName eq "bjensen")`,
+		`not userName eq "bjensen"`,
+		`not (userName eq "bjensen"`,
+		`emails[type eq "work"`,
+		`emails[type eq "work"]]`,
+		`"bjensen" eq userName`,
+		`userName eq ("bjensen")`,
+		`userName eq "\x"`,
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseFilter(input)
+			if err == nil {
+				t.Fatal("ParseFilter succeeded, want an error")
+			}
+
+			var scimErr *Error
+			if !errors.As(err, &scimErr) {
+				t.Fatalf("error %v is not a *Error", err)
+			}
+			if scimErr.StatusCode() != http.StatusBadRequest || scimErr.ScimType != ScimTypeInvalidFilter {
+				t.Errorf("got status %d and scimType %q, want %d and %q",
+					scimErr.StatusCode(), scimErr.ScimType, http.StatusBadRequest, ScimTypeInvalidFilter)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package scim
+
+import (
+	"reflect"
+	"strings"
+)
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type patchPath struct {
+	attr    string
+	filter  Filter
+	subAttr string
+}
+
+// ApplyPatch applies PATCH operations (RFC 7644 section 3.5.2) to the JSON
+// representation of a resource in place.
+func ApplyPatch(resource map[string]interface{}, ops []PatchOperation) error {
+	for _, op := range ops {
+		var path *patchPath
+		if op.Path != "" {
+			p, err := parsePatchPath(op.Path)
+			if err != nil {
+				return err
+			}
+			path = &p
+		}
+
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add":
+			err = applyAdd(resource, path, op.Value, false)
+		case "replace":
+			err = applyAdd(resource, path, op.Value, true)
+		case "remove":
+			err = applyRemove(resource, path, op.Value)
+		default:
+			err = badRequest(ScimTypeInvalidSyntax, "unsupported operation %q", op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parsePatchPath parses `attr`, `attr.sub`, `attr[filter]` and
+// `attr[filter].sub`, optionally prefixed with a schema URN.
+func parsePatchPath(raw string) (patchPath, error) {
+	prefix, rest, hasFilter := strings.Cut(raw, "[")
+	if !hasFilter {
+		p := parseAttrPath(raw)
+		return patchPath{attr: p.attr, subAttr: p.subAttr}, nil
+	}
+
+	end := strings.LastIndex(rest, "]")
+	if end < 0 {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid path %q", raw)
+	}
+
+	filter, err := ParseFilter(rest[:end])
+	if err != nil {
+		return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid path %q", raw)
+	}
+
+	path := patchPath{attr: parseAttrPath(prefix).attr, filter: filter}
+	if after := rest[end+1:]; after != "" {
+		sub, ok := strings.CutPrefix(after, ".")
+		if !ok || sub == "" {
+			return patchPath{}, badRequest(ScimTypeInvalidPath, "invalid path %q", raw)
+		}
+		path.subAttr = sub
+	}
+
+	return path, nil
+}
+
+func applyAdd(resource map[string]interface{}, path *patchPath, value interface{}, replace bool) error {
+	if path == nil {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return badRequest(ScimTypeInvalidValue, "value must be an object when no path is given")
+		}
+		for k, v := range values {
+			p := parseAttrPath(k)
+			if err := applyAdd(resource, &patchPath{attr: p.attr, subAttr: p.subAttr}, v, replace); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if path.filter != nil {
+		matched := 0
+		for _, element := range matchingElements(resource, path) {
+			matched++
+			if path.subAttr != "" {
+				setKey(element, path.subAttr, value)
+				continue
+			}
+			fields, ok := value.(map[string]interface{})
+			if !ok {
+				return badRequest(ScimTypeInvalidValue, "value must be an object for %q", path.attr)
+			}
+			for k, v := range fields {
+				setKey(element, k, v)
+			}
+		}
+		if matched == 0 {
+			return badRequest(ScimTypeNoTarget, "no values match the path filter")
+		}
+		return nil
+	}
+
+	if path.subAttr != "" {
+		complex, ok := lookup(resource, path.attr).(map[string]interface{})
+		if !ok {
+			complex = map[string]interface{}{}
+			setKey(resource, path.attr, complex)
+		}
+		setKey(complex, path.subAttr, value)
+		return nil
+	}
+
+	existing, isList := lookup(resource, path.attr).([]interface{})
+	added, valueIsList := value.([]interface{})
+	if !replace && isList && valueIsList {
+		for _, v := range added {
+			if !containsValue(existing, v) {
+				existing = append(existing, v)
+			}
+		}
+		setKey(resource, path.attr, existing)
+		return nil
+	}
+
+	setKey(resource, path.attr, value)
+	return nil
+}
+
+func applyRemove(resource map[string]interface{}, path *patchPath, value interface{}) error {
+	if path == nil {
+		return badRequest(ScimTypeNoTarget, "path is required for remove")
+	}
+
+	if path.filter == nil && path.subAttr == "" {
+		existing, isList := lookup(resource, path.attr).([]interface{})
+		removed, valueIsList := value.([]interface{})
+		if isList && valueIsList {
+			kept := existing[:0]
+			for _, v := range existing {
+				if !containsValue(removed, v) {
+					kept = append(kept, v)
+				}
+			}
+			setKey(resource, path.attr, kept)
+			return nil
+		}
+		deleteKey(resource, path.attr)
+		return nil
+	}
+
+	if path.filter == nil {
+		if complex, ok := lookup(resource, path.attr).(map[string]interface{}); ok {
+			deleteKey(complex, path.subAttr)
+		}
+		return nil
+	}
+
+	list, _ := lookup(resource, path.attr).([]interface{})
+	kept := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		element, ok := item.(map[string]interface{})
+		if !ok || !path.filter.Match(element) {
+			kept = append(kept, item)
+			continue
+		}
+		if path.subAttr != "" {
+			deleteKey(element, path.subAttr)
+			kept = append(kept, element)
+		}
+	}
+	setKey(resource, path.attr, kept)
+
+	return nil
+}
+
+func matchingElements(resource map[string]interface{}, path *patchPath) []map[string]interface{} {
+	list, _ := lookup(resource, path.attr).([]interface{})
+
+	var matched []map[string]interface{}
+	for _, item := range list {
+		if element, ok := item.(map[string]interface{}); ok && path.filter.Match(element) {
+			matched = append(matched, element)
+		}
+	}
+	return matched
+}
+
+// containsValue reports whether list holds v. Complex values are compared by
+// their "value" sub-attribute, which identifies members and emails.
+func containsValue(list []interface{}, v interface{}) bool {
+	want, isComplex := v.(map[string]interface{})
+	for _, item := range list {
+		if isComplex {
+			if element, ok := item.(map[string]interface{}); ok && reflect.DeepEqual(lookup(element, "value"), lookup(want, "value")) {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func setKey(m map[string]interface{}, name string, value interface{}) {
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			m[k] = value
+			return
+		}
+	}
+	m[name] = value
+}
+
+func deleteKey(m map[string]interface{}, name string) {
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			delete(m, k)
+		}
+	}
+}
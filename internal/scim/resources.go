@@ -0,0 +1,322 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+
+	ContentType = "application/scim+json"
+)
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+	Version      string     `json:"version,omitempty"`
+}
+
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type MultiValued struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type User struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	UserName    string        `json:"userName"`
+	Name        *Name         `json:"name,omitempty"`
+	DisplayName string        `json:"displayName,omitempty"`
+	Emails      []MultiValued `json:"emails,omitempty"`
+	Roles       []MultiValued `json:"roles,omitempty"`
+	Groups      []MultiValued `json:"groups,omitempty"`
+	Active      *bool         `json:"active,omitempty"`
+	Meta        *Meta         `json:"meta,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []MultiValued `json:"members,omitempty"`
+	Meta        *Meta         `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// ETag returns the weak entity tag of a resource version.
+func ETag(updatedAt time.Time) string {
+	return fmt.Sprintf(`W/"%s"`, strconv.FormatInt(updatedAt.UnixNano(), 36))
+}
+
+func UserFromDomain(user domain.User, baseURL string) User {
+	active := user.Status == domain.StatusActive
+	created, modified := user.CreatedAt, user.UpdatedAt
+
+	return User{
+		Schemas:     []string{SchemaUser},
+		ID:          user.ID,
+		UserName:    user.Email,
+		Name:        &Name{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []MultiValued{{Value: user.Email, Type: "work", Primary: true}},
+		Roles:       []MultiValued{{Value: user.Role, Primary: true}},
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     baseURL + "/Users/" + user.ID,
+			Version:      ETag(user.UpdatedAt),
+		},
+	}
+}
+
+func GroupFromDomain(group domain.Group, baseURL string) Group {
+	created, modified := group.CreatedAt, group.UpdatedAt
+
+	members := make([]MultiValued, 0, len(group.Members))
+	for _, m := range group.Members {
+		members = append(members, MultiValued{
+			Value:   m.UserID,
+			Display: m.Name,
+			Type:    "User",
+			Ref:     baseURL + "/Users/" + m.UserID,
+		})
+	}
+
+	return Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          group.ID,
+		DisplayName: group.Name,
+		Members:     members,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     baseURL + "/Groups/" + group.ID,
+			Version:      ETag(group.UpdatedAt),
+		},
+	}
+}
+
+// Email returns the primary email, falling back to the first email and then to
+// userName when it looks like an address.
+func (u User) Email() string {
+	for _, e := range u.Emails {
+		if e.Primary && e.Value != "" {
+			return e.Value
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Value != "" {
+			return e.Value
+		}
+	}
+	if strings.Contains(u.UserName, "@") {
+		return u.UserName
+	}
+	return ""
+}
+
+func (u User) FullName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+func (u User) Role() string {
+	for _, r := range u.Roles {
+		if r.Primary && r.Value != "" {
+			return r.Value
+		}
+	}
+	if len(u.Roles) > 0 {
+		return u.Roles[0].Value
+	}
+	return ""
+}
+
+func (u User) CreateRequest() (domain.CreateUserRequest, error) {
+	req := domain.CreateUserRequest{
+		Email: u.Email(),
+		Name:  u.FullName(),
+		Role:  u.Role(),
+
+		EmailVerified: true,
+	}
+
+	if req.Email == "" || req.Name == "" {
+		return domain.CreateUserRequest{}, badRequest(ScimTypeInvalidValue, "an email and a name are required")
+	}
+
+	if u.Active != nil && !*u.Active {
+		req.Disabled = true
+	}
+
+	return req, nil
+}
+
+// UpdateRequest converts a full replacement of the user into an update request.
+// The active flag is not part of it and is applied as a status transition.
+func (u User) UpdateRequest() (domain.UpdateUserRequest, error) {
+	req := domain.CreateUserRequest{
+		Email: u.Email(),
+		Name:  u.FullName(),
+		Role:  u.Role(),
+	}
+
+	if req.Email == "" || req.Name == "" {
+		return domain.UpdateUserRequest{}, badRequest(ScimTypeInvalidValue, "an email and a name are required")
+	}
+
+	return domain.UpdateUserRequest{
+		Email: &req.Email,
+		Name:  &req.Name,
+		Role:  req.Role,
+
+		EmailVerified: true,
+	}, nil
+}
+
+func (g Group) MemberIDs() []string {
+	ids := make([]string, 0, len(g.Members))
+	for _, m := range g.Members {
+		if m.Value != "" {
+			ids = append(ids, m.Value)
+		}
+	}
+	return ids
+}
+
+func (g Group) CreateRequest() (domain.CreateGroupRequest, error) {
+	if g.DisplayName == "" {
+		return domain.CreateGroupRequest{}, badRequest(ScimTypeInvalidValue, "displayName is required")
+	}
+
+	return domain.CreateGroupRequest{
+		Name:      g.DisplayName,
+		MemberIDs: g.MemberIDs(),
+	}, nil
+}
+
+func (g Group) UpdateRequest() (domain.UpdateGroupRequest, error) {
+	req, err := g.CreateRequest()
+	if err != nil {
+		return domain.UpdateGroupRequest{}, err
+	}
+
+	return domain.UpdateGroupRequest{
+		Name:      &req.Name,
+		MemberIDs: &req.MemberIDs,
+	}, nil
+}
+
+// ToMap returns the generic JSON representation of a resource, which is what
+// filters and PATCH operations work on.
+func ToMap(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource: %w", err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resource: %w", err)
+	}
+
+	return m, nil
+}
+
+// FromMap decodes a patched resource back into dst. Some providers send
+// booleans as strings, e.g. "active": "False", so those are normalized first.
+func FromMap(m map[string]interface{}, dst interface{}) error {
+	if s, ok := lookup(m, "active").(string); ok {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return badRequest(ScimTypeInvalidValue, "active must be a boolean")
+		}
+		setKey(m, "active", b)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource: %w", err)
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		return badRequest(ScimTypeInvalidValue, "invalid resource: %v", err)
+	}
+
+	return nil
+}
+
+// Paginate applies 1-based startIndex and count query parameters.
+func Paginate(resources []interface{}, startIndex, count int) ListResponse {
+	total := len(resources)
+
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	from := min(startIndex-1, total)
+	to := min(from+count, total)
+
+	page := resources[from:to]
+	if page == nil {
+		page = []interface{}{}
+	}
+
+	return ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}
+}
+
+func NotFound(resourceType, id string) *Error {
+	return NewError(http.StatusNotFound, "", "%s %s not found", resourceType, id)
+}
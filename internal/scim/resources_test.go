@@ -0,0 +1,70 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUserCreateRequest(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantEmail    string
+		wantName     string
+		wantDisabled bool
+		wantErr      bool
+	}{
+		{
+			name:      "primary email",
+			body:      `{"userName":"bjensen","displayName":"Barbara Jensen","emails":[{"value":"b@home.org"},{"value":"b@example.com","primary":true}]}`,
+			wantEmail: "b@example.com",
+			wantName:  "Barbara Jensen",
+		},
+		{
+			name:      "userName as email",
+			body:      `{"userName":"b@example.com","name":{"givenName":"Barbara","familyName":"Jensen"},"active":true}`,
+			wantEmail: "b@example.com",
+			wantName:  "Barbara Jensen",
+		},
+		{
+			name:         "inactive",
+			body:         `{"userName":"b@example.com","displayName":"Barbara Jensen","active":false}`,
+			wantEmail:    "b@example.com",
+			wantName:     "Barbara Jensen",
+			wantDisabled: true,
+		},
+		{
+			name:    "no email",
+			body:    `{"userName":"bjensen","displayName":"Barbara Jensen"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user User
+			if err := json.Unmarshal([]byte(tt.body), &user); err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := user.CreateRequest()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("CreateRequest succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateRequest: %v", err)
+			}
+
+			if req.Email != tt.wantEmail || req.Name != tt.wantName || req.Disabled != tt.wantDisabled {
+				t.Errorf("got email %q, name %q, disabled %v; want %q, %q, %v",
+					req.Email, req.Name, req.Disabled, tt.wantEmail, tt.wantName, tt.wantDisabled)
+			}
+			if !req.EmailVerified {
+				t.Error("provisioned email is not marked as verified")
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// RecordLogin records a successful login. With Redis the timestamp is buffered
+// and written by FlushActivity, otherwise it is written directly. Failures
+// are logged and never fail the login.
+func (uc *UseCase) RecordLogin(ctx context.Context, userID string) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.RecordLogin")
+	defer span.End()
+
+	now := time.Now()
+
+	if uc.activity != nil {
+		err := uc.activity.RecordLogin(ctx, userID, now)
+		if err == nil {
+			return
+		}
+		slog.WarnContext(ctx, "failed to buffer login, writing it directly", "error", err, "user_id", userID)
+	}
+
+	activity := domain.Activity{UserID: userID, LastSeenAt: now, LastLoginAt: &now}
+	if err := uc.repository.RecordActivity(ctx, []domain.Activity{activity}); err != nil {
+		slog.ErrorContext(ctx, "failed to record login", "error", err, "user_id", userID)
+	}
+}
+
+// RecordActivity records that an authenticated user made a request. It is
+// throttled per user in Redis and a no-op without Redis, so that requests
+// never cause database writes.
+func (uc *UseCase) RecordActivity(ctx context.Context, userID string) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.RecordActivity")
+	defer span.End()
+
+	if uc.activity == nil {
+		return
+	}
+
+	if _, err := uc.activity.TouchSeen(ctx, userID, time.Now(), uc.cfg.Activity.SeenThrottle); err != nil {
+		slog.WarnContext(ctx, "failed to record activity", "error", err, "user_id", userID)
+	}
+}
+
+// FlushActivity writes the activity buffered in Redis to the database in
+// batches. Batches that could not be written are put back for the next run.
+func (uc *UseCase) FlushActivity(ctx context.Context) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.FlushActivity")
+	defer span.End()
+
+	if uc.activity == nil {
+		return nil
+	}
+
+	activity, err := uc.activity.Drain(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to drain activity: %w", err)
+	}
+
+	batch := max(uc.cfg.Activity.FlushBatch, 1)
+	for from := 0; from < len(activity); from += batch {
+		to := min(from+batch, len(activity))
+
+		if err := uc.repository.RecordActivity(ctx, activity[from:to]); err != nil {
+			if restoreErr := uc.activity.Restore(ctx, activity[from:]); restoreErr != nil {
+				slog.ErrorContext(ctx, "failed to restore activity, updates are lost", "error", restoreErr, "count", len(activity)-from)
+			}
+			return fmt.Errorf("failed to flush activity: %w", err)
+		}
+	}
+
+	if len(activity) > 0 {
+		slog.InfoContext(ctx, "activity flushed", "count", len(activity))
+	}
+	return nil
+}
+
+// CheckInactivity warns active users without activity for longer than the
+// configured warning threshold, and suspends users who are past the
+// suspension threshold and were warned at least the difference between both
+// thresholds ago. Buffered activity is flushed before. A dry run only
+// reports what would happen.
+func (uc *UseCase) CheckInactivity(ctx context.Context, dryRun bool) (domain.InactivityReport, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.CheckInactivity")
+	defer span.End()
+
+	now := time.Now()
+	warnAfter, suspendAfter := uc.cfg.Activity.InactiveWarnAfter, uc.cfg.Activity.InactiveSuspendAfter
+	grace := max(suspendAfter-warnAfter, 0)
+
+	// Flush first, so that users seen since the last flush are not taken
+	// for inactive. Without a complete picture nobody is warned or suspended.
+	if err := uc.FlushActivity(ctx); err != nil {
+		return domain.InactivityReport{}, err
+	}
+
+	report := domain.InactivityReport{
+		DryRun:    dryRun,
+		CheckedAt: now,
+		Warned:    []domain.InactiveUser{},
+		Suspended: []domain.InactiveUser{},
+	}
+
+	users, err := uc.repository.ListInactive(ctx, now.Add(-warnAfter))
+	if err != nil {
+		return domain.InactivityReport{}, fmt.Errorf("failed to get inactive users: %w", err)
+	}
+
+	for _, user := range users {
+		switch {
+		case user.WarnedAt == nil:
+			if !dryRun {
+				if err := uc.warnInactiveUser(ctx, user, now.Add(grace)); err != nil {
+					slog.ErrorContext(ctx, "failed to warn inactive user", "error", err, "user_id", user.ID)
+					continue
+				}
+			}
+			report.Warned = append(report.Warned, user)
+
+		case user.LastActiveAt.Before(now.Add(-suspendAfter)) && !user.WarnedAt.After(now.Add(-grace)):
+			if !dryRun {
+				reason := fmt.Sprintf("inactive since %s", user.LastActiveAt.Format(time.DateOnly))
+				if _, err := uc.ChangeUserStatus(ctx, user.ID, "suspend", reason); err != nil {
+					if !errors.Is(err, domain.ErrInvalidTransition) && !errors.Is(err, domain.ErrNotFound) {
+						slog.ErrorContext(ctx, "failed to suspend inactive user", "error", err, "user_id", user.ID)
+					}
+					continue
+				}
+			}
+			report.Suspended = append(report.Suspended, user)
+		}
+	}
+
+	slog.InfoContext(ctx, "inactivity check finished", "dry_run", dryRun, "warned", len(report.Warned), "suspended", len(report.Suspended))
+	return report, nil
+}
+
+// SuspendInactiveUsers runs CheckInactivity in the configured mode. It is meant
+// to be run periodically.
+func (uc *UseCase) SuspendInactiveUsers(ctx context.Context) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.SuspendInactiveUsers")
+	defer span.End()
+
+	_, err := uc.CheckInactivity(ctx, uc.cfg.Activity.InactiveDryRun)
+	return err
+}
+
+func (uc *UseCase) warnInactiveUser(ctx context.Context, user domain.InactiveUser, suspendAt time.Time) error {
+	if err := uc.repository.MarkInactivityWarned(ctx, user.ID, time.Now()); err != nil {
+		return err
+	}
+
+	uc.notify(ctx, domain.Notification{
+		To:      user.Email,
+		Subject: "Your account is inactive",
+		Body: fmt.Sprintf("You have not used your account since %s. It will be suspended on %s unless you sign in before.",
+			user.LastActiveAt.Format(time.DateOnly), suspendAt.Format(time.DateOnly)),
+	})
+
+	uc.publishEvent(ctx, "inactivity_warning", user.ID, nil)
+	return nil
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/highway-to-Golang/user-service/internal/blob"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/imaging"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// AvatarUploadLimit is the largest avatar upload in bytes.
+func (uc *UseCase) AvatarUploadLimit() int64 {
+	return uc.cfg.Avatar.MaxBytes
+}
+
+// UploadAvatar stores resized variants of an uploaded image as the user's new
+// avatar. Each upload gets a new version so that avatar URLs never change
+// content and can be cached indefinitely.
+func (uc *UseCase) UploadAvatar(ctx context.Context, id string, data []byte) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.UploadAvatar")
+	defer span.End()
+
+	if uc.blobs == nil {
+		return domain.User{}, fmt.Errorf("%w: avatars are not enabled", domain.ErrAvatarsNotEnabled)
+	}
+
+	if int64(len(data)) > uc.cfg.Avatar.MaxBytes {
+		return domain.User{}, fmt.Errorf("%w: avatar exceeds %d bytes", domain.ErrAvatarTooLarge, uc.cfg.Avatar.MaxBytes)
+	}
+
+	user, err := uc.GetUser(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	variants, err := imaging.Resize(data, uc.cfg.Avatar.Sizes, uc.cfg.Avatar.MaxDimension)
+	if err != nil {
+		switch {
+		case errors.Is(err, imaging.ErrUnsupportedFormat):
+			return domain.User{}, fmt.Errorf("%w: %v", domain.ErrUnsupportedAvatarImage, err)
+		case errors.Is(err, imaging.ErrTooLarge):
+			return domain.User{}, fmt.Errorf("%w: %v", domain.ErrAvatarTooLarge, err)
+		}
+		return domain.User{}, fmt.Errorf("failed to process avatar: %w", err)
+	}
+
+	version, err := uuid.NewV7()
+	if err != nil {
+		return domain.User{}, fmt.Errorf("failed to create avatar version: %w", err)
+	}
+
+	urls := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		name := fmt.Sprintf("%d.%s", variant.Size, variant.Extension)
+		if err := uc.blobs.Put(ctx, avatarKey(id, version.String(), name), variant.ContentType, variant.Data); err != nil {
+			uc.deleteAvatarVersion(ctx, id, version.String())
+			return domain.User{}, fmt.Errorf("failed to store avatar: %w", err)
+		}
+		urls[strconv.Itoa(variant.Size)] = uc.avatarURL(id, version.String(), name)
+	}
+
+	if err := uc.repository.SetAvatar(ctx, id, version.String(), urls); err != nil {
+		uc.deleteAvatarVersion(ctx, id, version.String())
+		return domain.User{}, fmt.Errorf("failed to save avatar: %w", err)
+	}
+
+	if user.AvatarVersion != "" {
+		uc.deleteAvatarVersion(ctx, id, user.AvatarVersion)
+	}
+
+	uc.publishEvent(ctx, "avatar_updated", id, nil)
+
+	return uc.GetUser(ctx, id)
+}
+
+func (uc *UseCase) DeleteAvatar(ctx context.Context, id string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.DeleteAvatar")
+	defer span.End()
+
+	user, err := uc.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if user.AvatarVersion == "" {
+		return domain.ErrNotFound
+	}
+
+	if err := uc.repository.SetAvatar(ctx, id, "", nil); err != nil {
+		return fmt.Errorf("failed to remove avatar: %w", err)
+	}
+
+	uc.deleteAvatarVersion(ctx, id, user.AvatarVersion)
+	uc.publishEvent(ctx, "avatar_deleted", id, nil)
+
+	return nil
+}
+
+// OpenAvatar opens a stored avatar variant. Callers must close the body.
+func (uc *UseCase) OpenAvatar(ctx context.Context, id, version, name string) (blob.Object, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.OpenAvatar")
+	defer span.End()
+
+	if uc.blobs == nil {
+		return blob.Object{}, domain.ErrNotFound
+	}
+
+	for _, part := range []string{id, version, name} {
+		if part == "" || strings.ContainsAny(part, `/\`) || strings.Contains(part, "..") {
+			return blob.Object{}, domain.ErrNotFound
+		}
+	}
+
+	object, err := uc.blobs.Open(ctx, avatarKey(id, version, name))
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return blob.Object{}, domain.ErrNotFound
+		}
+		return blob.Object{}, fmt.Errorf("failed to open avatar: %w", err)
+	}
+
+	return object, nil
+}
+
+func (uc *UseCase) deleteAvatarVersion(ctx context.Context, id, version string) {
+	if err := uc.blobs.DeletePrefix(ctx, avatarKey(id, version, "")); err != nil {
+		slog.WarnContext(ctx, "failed to delete avatar files", "error", err, "user_id", id, "version", version)
+	}
+}
+
+func (uc *UseCase) avatarURL(id, version, name string) string {
+	return strings.TrimSuffix(uc.cfg.Avatar.BaseURL, "/") + "/avatars/" + id + "/" + version + "/" + name
+}
+
+func avatarKey(id, version, name string) string {
+	return strings.TrimSuffix("avatars/"+id+"/"+version+"/"+name, "/")
+}
@@ -5,12 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/highway-to-Golang/user-service/internal/auth"
 	"github.com/highway-to-Golang/user-service/internal/domain"
 	"github.com/highway-to-Golang/user-service/internal/errors"
+	"github.com/highway-to-Golang/user-service/internal/metrics"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
 func (uc *UseCase) CreateUser(ctx context.Context, idempotencyKey string, req domain.CreateUserRequest) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.CreateUser")
+	defer span.End()
+
 	if idempotencyKey != "" && uc.cfg.Redis.URL != "" && uc.idempotencyStorage != nil {
 		cached, err := uc.idempotencyStorage.GetResult(ctx, idempotencyKey)
 		if err != nil {
@@ -22,6 +29,7 @@ func (uc *UseCase) CreateUser(ctx context.Context, idempotencyKey string, req do
 			if err := json.Unmarshal(cached, &user); err != nil {
 				slog.ErrorContext(ctx, "json.Unmarshal", "err", err)
 			} else {
+				metrics.IdempotencyRequests.WithLabelValues("hit").Inc()
 				return user, nil
 			}
 		}
@@ -32,6 +40,7 @@ func (uc *UseCase) CreateUser(ctx context.Context, idempotencyKey string, req do
 		}
 
 		if !lockAcquired {
+			metrics.IdempotencyRequests.WithLabelValues("in_progress").Inc()
 			return domain.User{}, errors.ErrRequestAlreadyInProgress
 		}
 
@@ -52,27 +61,62 @@ func (uc *UseCase) CreateUser(ctx context.Context, idempotencyKey string, req do
 			if err := json.Unmarshal(cached, &user); err != nil {
 				slog.ErrorContext(ctx, "json.Unmarshal (second check)", "err", err)
 			} else {
+				metrics.IdempotencyRequests.WithLabelValues("hit").Inc()
 				return user, nil
 			}
 		}
+
+		metrics.IdempotencyRequests.WithLabelValues("miss").Inc()
 	}
 
-	if req.Email == "" || req.Name == "" {
-		return domain.User{}, fmt.Errorf("email and name are required")
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return domain.User{}, err
 	}
 
 	if req.Role == "" {
 		req.Role = "user"
 	}
 
+	if err := uc.ensureEmailAvailable(ctx, req.Email); err != nil {
+		return domain.User{}, err
+	}
+
 	user, err := domain.NewUser(req.Name, req.Email, req.Role)
 	if err != nil {
-		slog.Error("failed to create user", "error", err)
+		slog.ErrorContext(ctx, "failed to create user", "error", err)
 		return domain.User{}, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	if req.Username != "" {
+		user.Username, user.UsernameKey, err = uc.claimUsername(ctx, user.ID, req.Username)
+		if err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	if req.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	} else {
+		user.Status = domain.StatusPending
+	}
+
+	if req.Disabled {
+		user.Status = domain.StatusDisabled
+	}
+
+	if req.Password != "" {
+		hash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to hash password", "error", err)
+			return domain.User{}, fmt.Errorf("failed to create user: %w", err)
+		}
+		user.PasswordHash = hash
+	}
+
 	if err := uc.repository.Create(ctx, user); err != nil {
-		slog.Error("failed to save user", "error", err)
+		slog.ErrorContext(ctx, "failed to save user", "error", err)
 		return domain.User{}, fmt.Errorf("failed to save user: %w", err)
 	}
 
@@ -89,11 +133,15 @@ func (uc *UseCase) CreateUser(ctx context.Context, idempotencyKey string, req do
 
 	if uc.cfg.NATS.Enabled && uc.eventSink != nil {
 		if err := uc.eventSink.Publish(ctx, "create"); err != nil {
-			slog.Warn("failed to publish event", "error", err, "method", "create")
+			slog.WarnContext(ctx, "failed to publish event", "error", err, "method", "create")
 		}
 	}
 
-	slog.Info("user created successfully", "user_id", user.ID, "email", user.Email)
+	if !user.IsEmailVerified() {
+		uc.sendEmailVerification(ctx, user, user.Email)
+	}
+
+	slog.InfoContext(ctx, "user created successfully", "user_id", user.ID, "email", user.Email)
 
 	return user, nil
 }
@@ -3,28 +3,27 @@ package usecase
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log/slog"
 
 	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
+// DeleteUser soft-deletes a user by running the delete lifecycle action. The
+// user keeps its data and username and can be restored. Deleting a user that
+// is already deleted returns domain.ErrNotFound.
 func (uc *UseCase) DeleteUser(ctx context.Context, id string) error {
-	slog.Info("deleting user", "id", id)
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.DeleteUser")
+	defer span.End()
 
-	if err := uc.repository.Delete(ctx, id); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			slog.Warn("user not found for deletion", "user_id", id)
-			return domain.ErrNotFound
-		}
-		slog.Error("failed to delete user", "error", err, "user_id", id)
-		return fmt.Errorf("failed to delete user: %w", err)
-	}
+	slog.InfoContext(ctx, "deleting user", "id", id)
 
-	if uc.cfg.NATS.Enabled && uc.eventSink != nil {
-		if err := uc.eventSink.Publish(ctx, "delete"); err != nil {
-			slog.Warn("failed to publish event", "error", err, "method", "delete")
+	if _, err := uc.ChangeUserStatus(ctx, id, "delete", "user deleted"); err != nil {
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			slog.WarnContext(ctx, "user already deleted", "user_id", id)
+			return domain.ErrNotFound
 		}
+		return err
 	}
 
 	return nil
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// RequestEmailVerification sends a new verification link for the pending
+// email if a change is in progress, or for the current email otherwise.
+func (uc *UseCase) RequestEmailVerification(ctx context.Context, id string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.RequestEmailVerification")
+	defer span.End()
+
+	if err := authorizeSelfOrAdmin(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := uc.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case user.PendingEmail != "":
+		uc.sendEmailVerification(ctx, user, user.PendingEmail)
+	case !user.IsEmailVerified():
+		uc.sendEmailVerification(ctx, user, user.Email)
+	default:
+		return fmt.Errorf("%w: email is already verified", domain.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// VerifyEmail redeems a verification token. It either marks one of the user's
+// addresses verified or completes a pending email change. Pending users become
+// active once their primary address is verified. Unknown, used, expired or
+// superseded tokens return domain.ErrNotFound.
+func (uc *UseCase) VerifyEmail(ctx context.Context, token string) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.VerifyEmail")
+	defer span.End()
+
+	if token == "" {
+		return domain.User{}, fmt.Errorf("%w: token is required", domain.ErrInvalidInput)
+	}
+
+	verification, err := uc.emailVerificationRepository.Consume(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to consume verification token: %w", err)
+	}
+
+	user, err := uc.GetUser(ctx, verification.UserID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	method := "email_verified"
+	if user.PendingEmail != "" && verification.Email == user.PendingEmail {
+		if err := uc.repository.ConfirmEmailChange(ctx, user.ID, verification.Email); err != nil {
+			return domain.User{}, fmt.Errorf("failed to confirm email change: %w", err)
+		}
+		method = "email_changed"
+	} else if err := uc.repository.MarkEmailVerified(ctx, user.ID, verification.Email); err != nil {
+		// The address may have been removed or replaced since the token was sent.
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	verified, err := uc.GetUser(ctx, user.ID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	uc.publishEvent(ctx, method, user.ID, nil)
+
+	if verified.Status == domain.StatusPending && verified.IsEmailVerified() {
+		verified, err = uc.ChangeUserStatus(ctx, user.ID, "activate", "email verified")
+		if err != nil {
+			return domain.User{}, fmt.Errorf("failed to activate user: %w", err)
+		}
+	}
+
+	return verified, nil
+}
+
+// requestEmailChange sends a verification link to the new address and lets
+// the previous address know that a change was requested.
+func (uc *UseCase) requestEmailChange(ctx context.Context, user domain.User, previousEmail string) {
+	uc.sendEmailVerification(ctx, user, user.PendingEmail)
+
+	uc.notify(ctx, domain.Notification{
+		To:      previousEmail,
+		Subject: "Email change requested",
+		Body: fmt.Sprintf("A change of your account email to %s was requested. "+
+			"If this was not you, contact support; the change only applies once the new address is confirmed.",
+			user.PendingEmail),
+	})
+
+	uc.publishEvent(ctx, "email_change_requested", user.ID, nil)
+}
+
+func (uc *UseCase) sendEmailVerification(ctx context.Context, user domain.User, email string) {
+	if uc.emailVerificationRepository == nil {
+		return
+	}
+
+	token, err := auth.RandomToken(32)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create verification token", "error", err, "user_id", user.ID)
+		return
+	}
+
+	verification := domain.EmailVerification{
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+		Email:     email,
+		ExpiresAt: time.Now().Add(uc.cfg.EmailVerification.TTL),
+	}
+	if err := uc.emailVerificationRepository.Create(ctx, verification); err != nil {
+		slog.ErrorContext(ctx, "failed to save verification token", "error", err, "user_id", user.ID)
+		return
+	}
+
+	link, err := url.Parse(uc.cfg.EmailVerification.URL)
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid email verification url", "error", err)
+		return
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	uc.notify(ctx, domain.Notification{
+		To:      email,
+		Subject: "Confirm your email address",
+		Body:    "Confirm this address for your account using the link below.",
+		Link:    link.String(),
+	})
+}
+
+// ensureEmailAvailable returns domain.ErrConflict if another user already
+// uses email.
+func (uc *UseCase) ensureEmailAvailable(ctx context.Context, email string) error {
+	_, err := uc.repository.GetByEmail(ctx, email)
+	if err == nil {
+		return fmt.Errorf("%w: user with this email", domain.ErrConflict)
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("failed to check existing user: %w", err)
+	}
+	return nil
+}
+
+func (uc *UseCase) notify(ctx context.Context, notification domain.Notification) {
+	if uc.notifier == nil {
+		slog.WarnContext(ctx, "no notifier configured, notification not sent", "subject", notification.Subject)
+		return
+	}
+
+	if err := uc.notifier.Send(ctx, notification); err != nil {
+		slog.ErrorContext(ctx, "failed to send notification", "error", err, "subject", notification.Subject)
+	}
+}
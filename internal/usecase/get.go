@@ -7,26 +7,57 @@ import (
 	"log/slog"
 
 	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
+// GetUser returns a user. Deleted users are not found, as in user lists.
 func (uc *UseCase) GetUser(ctx context.Context, id string) (domain.User, error) {
-	slog.Info("getting user", "id", id)
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetUser")
+	defer span.End()
+
+	slog.InfoContext(ctx, "getting user", "id", id)
 
 	user, err := uc.repository.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			slog.Warn("user not found", "user_id", id)
+			slog.WarnContext(ctx, "user not found", "user_id", id)
 			return domain.User{}, err
 		}
-		slog.Error("failed to get user", "error", err, "user_id", id)
+		slog.ErrorContext(ctx, "failed to get user", "error", err, "user_id", id)
 		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	if user.Status == domain.StatusDeleted {
+		return domain.User{}, domain.ErrNotFound
+	}
+
 	if uc.cfg.NATS.Enabled && uc.eventSink != nil {
 		if err := uc.eventSink.Publish(ctx, "get"); err != nil {
-			slog.Warn("failed to publish event", "error", err, "method", "get")
+			slog.WarnContext(ctx, "failed to publish event", "error", err, "method", "get")
 		}
 	}
 
 	return user, nil
 }
+
+// GetUsersByIDs returns the users with the given IDs keyed by ID. Unknown IDs
+// are missing from the result.
+func (uc *UseCase) GetUsersByIDs(ctx context.Context, ids []string) (map[string]domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetUsersByIDs")
+	defer span.End()
+
+	result := make(map[string]domain.User, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	users, err := uc.repository.GetAll(ctx, domain.UserFilter{IDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, user := range users {
+		result[user.ID] = user
+	}
+
+	return result, nil
+}
@@ -6,19 +6,25 @@ import (
 	"log/slog"
 
 	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
-func (uc *UseCase) GetAllUsers(ctx context.Context) ([]domain.User, error) {
-	slog.Info("getting all users")
+func (uc *UseCase) GetAllUsers(ctx context.Context, filter domain.UserFilter) ([]domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetAllUsers")
+	defer span.End()
 
-	users, err := uc.repository.GetAll(ctx)
+	slog.InfoContext(ctx, "getting all users")
+
+	users, err := uc.repository.GetAll(ctx, filter)
 	if err != nil {
-		slog.Error("failed to get users", "error", err)
+		slog.ErrorContext(ctx, "failed to get users", "error", err)
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
 
-	if err := uc.eventSink.Publish(ctx, "get_all"); err != nil {
-		slog.Warn("failed to publish event", "error", err, "method", "get_all")
+	if uc.cfg.NATS.Enabled && uc.eventSink != nil {
+		if err := uc.eventSink.Publish(ctx, "get_all"); err != nil {
+			slog.WarnContext(ctx, "failed to publish event", "error", err, "method", "get_all")
+		}
 	}
 
 	return users, nil
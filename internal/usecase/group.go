@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+func (uc *UseCase) CreateGroup(ctx context.Context, req domain.CreateGroupRequest) (domain.Group, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.CreateGroup")
+	defer span.End()
+
+	if req.Name == "" {
+		return domain.Group{}, fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+
+	group, err := domain.NewGroup(req.Name)
+	if err != nil {
+		return domain.Group{}, fmt.Errorf("failed to create group: %w", err)
+	}
+	group.Members = membersFromIDs(req.MemberIDs)
+
+	if err := uc.groupRepository.Create(ctx, group); err != nil {
+		slog.ErrorContext(ctx, "failed to save group", "error", err)
+		return domain.Group{}, fmt.Errorf("failed to save group: %w", err)
+	}
+
+	created, err := uc.groupRepository.GetByID(ctx, group.ID)
+	if err != nil {
+		return domain.Group{}, fmt.Errorf("failed to get created group: %w", err)
+	}
+
+	uc.publishGroupEvent(ctx, "group_create", created.ID)
+
+	return created, nil
+}
+
+func (uc *UseCase) GetGroup(ctx context.Context, id string) (domain.Group, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetGroup")
+	defer span.End()
+
+	group, err := uc.groupRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Group{}, domain.ErrNotFound
+		}
+		return domain.Group{}, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return group, nil
+}
+
+func (uc *UseCase) GetAllGroups(ctx context.Context) ([]domain.Group, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetAllGroups")
+	defer span.End()
+
+	groups, err := uc.groupRepository.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetUserGroups returns the groups of each given user keyed by user ID.
+func (uc *UseCase) GetUserGroups(ctx context.Context, userIDs []string) (map[string][]domain.Group, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetUserGroups")
+	defer span.End()
+
+	groups, err := uc.groupRepository.GetByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// GetGroupMembers returns the members of each given group keyed by group ID.
+func (uc *UseCase) GetGroupMembers(ctx context.Context, groupIDs []string) (map[string][]domain.GroupMember, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetGroupMembers")
+	defer span.End()
+
+	members, err := uc.groupRepository.GetMembers(ctx, groupIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (uc *UseCase) UpdateGroup(ctx context.Context, id string, req domain.UpdateGroupRequest) (domain.Group, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.UpdateGroup")
+	defer span.End()
+
+	group, err := uc.groupRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Group{}, domain.ErrNotFound
+		}
+		return domain.Group{}, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return domain.Group{}, fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+		}
+		group.Name = *req.Name
+	}
+	if req.MemberIDs != nil {
+		group.Members = membersFromIDs(*req.MemberIDs)
+	}
+
+	if err := uc.groupRepository.Update(ctx, group); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Group{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to update group", "error", err, "group_id", id)
+		return domain.Group{}, fmt.Errorf("failed to update group: %w", err)
+	}
+
+	updated, err := uc.groupRepository.GetByID(ctx, id)
+	if err != nil {
+		return domain.Group{}, fmt.Errorf("failed to get updated group: %w", err)
+	}
+
+	uc.publishGroupEvent(ctx, "group_update", id)
+
+	return updated, nil
+}
+
+func (uc *UseCase) DeleteGroup(ctx context.Context, id string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.DeleteGroup")
+	defer span.End()
+
+	if err := uc.groupRepository.Delete(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to delete group", "error", err, "group_id", id)
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	uc.publishGroupEvent(ctx, "group_delete", id)
+
+	return nil
+}
+
+func (uc *UseCase) publishGroupEvent(ctx context.Context, method, groupID string) {
+	uc.publishEvent(ctx, method, "", map[string]string{"group_id": groupID})
+}
+
+func membersFromIDs(ids []string) []domain.GroupMember {
+	members := make([]domain.GroupMember, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, domain.GroupMember{UserID: id})
+	}
+	return members
+}
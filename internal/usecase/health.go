@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// HealthCheck checks that a dependency, e.g. Postgres, is usable. Check must
+// return once ctx is done.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// BeginShutdown makes readiness fail, so that traffic is routed elsewhere
+// while in-flight requests drain.
+func (uc *UseCase) BeginShutdown() {
+	uc.shuttingDown.Store(true)
+}
+
+// CheckReadiness runs the health checks concurrently, each within the
+// configured timeout. Failing checks listed as optional in the configuration
+// degrade the service without making it unready.
+func (uc *UseCase) CheckReadiness(ctx context.Context) domain.HealthReport {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.CheckReadiness")
+	defer span.End()
+
+	report := domain.HealthReport{
+		Status: domain.HealthStatusOK,
+		Checks: make(map[string]domain.HealthCheckResult, len(uc.healthChecks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range uc.healthChecks {
+		wg.Go(func() {
+			result := uc.runHealthCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+		})
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == domain.HealthCheckPass {
+			continue
+		}
+		if result.Critical {
+			report.Status = domain.HealthStatusUnavailable
+		} else if report.Status == domain.HealthStatusOK {
+			report.Status = domain.HealthStatusDegraded
+		}
+	}
+
+	if uc.shuttingDown.Load() {
+		report.Status = domain.HealthStatusShuttingDown
+	}
+
+	return report
+}
+
+func (uc *UseCase) runHealthCheck(ctx context.Context, check HealthCheck) domain.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, uc.cfg.Health.CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Check(ctx)
+
+	result := domain.HealthCheckResult{
+		Status:     domain.HealthCheckPass,
+		Critical:   !slices.Contains(uc.cfg.Health.Optional, check.Name),
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = domain.HealthCheckFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
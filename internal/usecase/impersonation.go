@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// Impersonate issues a short-lived access token that lets an admin act as
+// another user. The token carries the admin as actor, and the session is
+// recorded and published as a security event.
+func (uc *UseCase) Impersonate(ctx context.Context, actor domain.Principal, userID string, req domain.ImpersonateRequest) (domain.ImpersonationResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.Impersonate")
+	defer span.End()
+
+	if req.Reason == "" {
+		return domain.ImpersonationResult{}, fmt.Errorf("%w: reason is required", domain.ErrInvalidInput)
+	}
+
+	if actor.Impersonated() {
+		return domain.ImpersonationResult{}, fmt.Errorf("%w: cannot impersonate while impersonating", domain.ErrForbidden)
+	}
+	if actor.UserID == userID {
+		return domain.ImpersonationResult{}, fmt.Errorf("%w: cannot impersonate yourself", domain.ErrInvalidInput)
+	}
+
+	user, err := uc.GetUser(ctx, userID)
+	if err != nil {
+		return domain.ImpersonationResult{}, err
+	}
+
+	if user.Role == domain.RoleAdmin {
+		return domain.ImpersonationResult{}, fmt.Errorf("%w: admins cannot be impersonated", domain.ErrForbidden)
+	}
+	if user.Status != domain.StatusActive {
+		return domain.ImpersonationResult{}, domain.ErrAccountInactive
+	}
+
+	sessionID, err := uuid.NewV7()
+	if err != nil {
+		return domain.ImpersonationResult{}, fmt.Errorf("failed to create session id: %w", err)
+	}
+
+	token, expiresAt, err := uc.tokens.IssueImpersonation(user.ID, user.Role, actor.UserID, sessionID.String(), uc.cfg.Impersonation.TTL)
+	if err != nil {
+		return domain.ImpersonationResult{}, fmt.Errorf("failed to issue impersonation token: %w", err)
+	}
+
+	session := domain.ImpersonationSession{
+		ID:        sessionID.String(),
+		ActorID:   actor.UserID,
+		UserID:    user.ID,
+		Reason:    req.Reason,
+		IP:        req.IP,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}
+	if err := uc.impersonationRepository.Create(ctx, session); err != nil {
+		return domain.ImpersonationResult{}, fmt.Errorf("failed to record impersonation session: %w", err)
+	}
+
+	slog.WarnContext(ctx, "impersonation session started",
+		"session_id", session.ID, "actor_id", actor.UserID, "user_id", user.ID)
+
+	uc.publishEvent(ctx, "impersonation_started", user.ID, map[string]string{
+		"actor_id":   actor.UserID,
+		"session_id": session.ID,
+		"reason":     req.Reason,
+	})
+
+	return domain.ImpersonationResult{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+		SessionID:   session.ID,
+	}, nil
+}
+
+func (uc *UseCase) ListImpersonationSessions(ctx context.Context, userID string) ([]domain.ImpersonationSession, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ListImpersonationSessions")
+	defer span.End()
+
+	sessions, err := uc.impersonationRepository.List(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impersonation sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListImpersonationSessionsByUserIDs returns the impersonation sessions of each
+// given user keyed by user ID.
+func (uc *UseCase) ListImpersonationSessionsByUserIDs(ctx context.Context, userIDs []string) (map[string][]domain.ImpersonationSession, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ListImpersonationSessionsByUserIDs")
+	defer span.End()
+
+	sessions, err := uc.impersonationRepository.ListByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get impersonation sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// AuthorizeImpersonatedWrite applies the configured write policy to a write
+// request made with an impersonation token. Writes are rejected with
+// domain.ErrForbidden in block mode, and allowed but published as a security
+// event in flag mode.
+func (uc *UseCase) AuthorizeImpersonatedWrite(ctx context.Context, principal domain.Principal, method, path string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.AuthorizeImpersonatedWrite")
+	defer span.End()
+
+	if uc.cfg.Impersonation.Writes != domain.ImpersonationWritesFlag {
+		slog.WarnContext(ctx, "write blocked during impersonation",
+			"session_id", principal.SessionID, "actor_id", principal.ActorID, "method", method, "path", path)
+		return fmt.Errorf("%w: write operations are not allowed while impersonating", domain.ErrForbidden)
+	}
+
+	slog.WarnContext(ctx, "write during impersonation",
+		"session_id", principal.SessionID, "actor_id", principal.ActorID, "method", method, "path", path)
+
+	uc.publishEvent(ctx, "impersonation_write", principal.UserID, map[string]string{
+		"actor_id":   principal.ActorID,
+		"session_id": principal.SessionID,
+		"method":     method,
+		"path":       path,
+	})
+
+	return nil
+}
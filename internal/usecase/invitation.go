@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+func (uc *UseCase) CreateInvitation(ctx context.Context, req domain.CreateInvitationRequest, invitedBy string) (domain.Invitation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.CreateInvitation")
+	defer span.End()
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || !strings.Contains(req.Email, "@") {
+		return domain.Invitation{}, fmt.Errorf("%w: a valid email is required", domain.ErrInvalidInput)
+	}
+
+	if req.Role == "" {
+		req.Role = domain.RoleUser
+	}
+	if !slices.Contains(domain.Roles, req.Role) {
+		return domain.Invitation{}, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidInput, req.Role)
+	}
+
+	if _, err := uc.repository.GetByEmail(ctx, req.Email); err == nil {
+		return domain.Invitation{}, fmt.Errorf("%w: user with this email", domain.ErrConflict)
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return domain.Invitation{}, fmt.Errorf("failed to check existing user: %w", err)
+	}
+
+	for _, groupID := range req.GroupIDs {
+		if _, err := uc.groupRepository.GetByID(ctx, groupID); err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return domain.Invitation{}, fmt.Errorf("%w: unknown group %q", domain.ErrInvalidInput, groupID)
+			}
+			return domain.Invitation{}, fmt.Errorf("failed to get group: %w", err)
+		}
+	}
+
+	invitation, err := domain.NewInvitation(req.Email, req.Role, req.GroupIDs, invitedBy, time.Now().Add(uc.cfg.Invitations.TTL))
+	if err != nil {
+		return domain.Invitation{}, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	token, err := auth.SignedToken(uc.cfg.Auth.TokenSecret, invitation.ID)
+	if err != nil {
+		return domain.Invitation{}, fmt.Errorf("failed to create invitation token: %w", err)
+	}
+	invitation.TokenHash = auth.HashToken(token)
+
+	if err := uc.invitationRepository.Create(ctx, invitation); err != nil {
+		slog.ErrorContext(ctx, "failed to save invitation", "error", err)
+		return domain.Invitation{}, fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	created, err := uc.invitationRepository.GetByID(ctx, invitation.ID)
+	if err != nil {
+		return domain.Invitation{}, fmt.Errorf("failed to get created invitation: %w", err)
+	}
+
+	uc.sendInvitation(ctx, created, token)
+	uc.publishInvitationEvent(ctx, "invitation_created", created.ID, invitedBy)
+
+	return created, nil
+}
+
+func (uc *UseCase) GetInvitation(ctx context.Context, id string) (domain.Invitation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetInvitation")
+	defer span.End()
+
+	invitation, err := uc.invitationRepository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Invitation{}, domain.ErrNotFound
+		}
+		return domain.Invitation{}, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (uc *UseCase) ListInvitations(ctx context.Context, status string) ([]domain.Invitation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ListInvitations")
+	defer span.End()
+
+	invitations, err := uc.invitationRepository.GetAll(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// ResendInvitation issues a new token for a pending or expired invitation,
+// extends its expiry and notifies the invitee again. The previous link stops
+// working.
+func (uc *UseCase) ResendInvitation(ctx context.Context, id, actorID string) (domain.Invitation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ResendInvitation")
+	defer span.End()
+
+	if _, err := uc.GetInvitation(ctx, id); err != nil {
+		return domain.Invitation{}, err
+	}
+
+	token, err := auth.SignedToken(uc.cfg.Auth.TokenSecret, id)
+	if err != nil {
+		return domain.Invitation{}, fmt.Errorf("failed to create invitation token: %w", err)
+	}
+
+	if err := uc.invitationRepository.Renew(ctx, id, auth.HashToken(token), time.Now().Add(uc.cfg.Invitations.TTL)); err != nil {
+		return domain.Invitation{}, fmt.Errorf("failed to renew invitation: %w", err)
+	}
+
+	invitation, err := uc.GetInvitation(ctx, id)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	uc.sendInvitation(ctx, invitation, token)
+	uc.publishInvitationEvent(ctx, "invitation_resent", id, actorID)
+
+	return invitation, nil
+}
+
+func (uc *UseCase) RevokeInvitation(ctx context.Context, id, actorID string) (domain.Invitation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.RevokeInvitation")
+	defer span.End()
+
+	if _, err := uc.GetInvitation(ctx, id); err != nil {
+		return domain.Invitation{}, err
+	}
+
+	if err := uc.invitationRepository.UpdateStatus(ctx, id, domain.InvitationPending, domain.InvitationRevoked); err != nil {
+		return domain.Invitation{}, fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+
+	invitation, err := uc.GetInvitation(ctx, id)
+	if err != nil {
+		return domain.Invitation{}, err
+	}
+
+	uc.publishInvitationEvent(ctx, "invitation_revoked", id, actorID)
+
+	return invitation, nil
+}
+
+// AcceptInvitation redeems an invitation token and creates the invited user.
+// Unknown, tampered or superseded tokens return domain.ErrNotFound.
+func (uc *UseCase) AcceptInvitation(ctx context.Context, req domain.AcceptInvitationRequest) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.AcceptInvitation")
+	defer span.End()
+
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return domain.User{}, err
+	}
+
+	id, ok := auth.VerifySignedToken(uc.cfg.Auth.TokenSecret, req.Token)
+	if !ok {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	invitation, err := uc.GetInvitation(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	tokenHash := auth.HashToken(req.Token)
+	if subtle.ConstantTimeCompare([]byte(tokenHash), []byte(invitation.TokenHash)) != 1 {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	if invitation.Status == domain.InvitationExpired ||
+		(invitation.Status == domain.InvitationPending && !time.Now().Before(invitation.ExpiresAt)) {
+		return domain.User{}, domain.ErrInvitationExpired
+	}
+
+	if err := uc.invitationRepository.Claim(ctx, id, tokenHash); err != nil {
+		return domain.User{}, fmt.Errorf("failed to claim invitation: %w", err)
+	}
+
+	user, err := uc.CreateUser(ctx, "", domain.CreateUserRequest{
+		Name:     req.Name,
+		Email:    invitation.Email,
+		Role:     invitation.Role,
+		Password: req.Password,
+
+		EmailVerified: true,
+	})
+	if err != nil {
+		if releaseErr := uc.invitationRepository.Release(ctx, id); releaseErr != nil {
+			slog.ErrorContext(ctx, "failed to release invitation", "error", releaseErr, "invitation_id", id)
+		}
+		return domain.User{}, err
+	}
+
+	if err := uc.invitationRepository.SetUserID(ctx, id, user.ID); err != nil {
+		slog.ErrorContext(ctx, "failed to link invitation to user", "error", err, "invitation_id", id, "user_id", user.ID)
+	}
+
+	for _, groupID := range invitation.GroupIDs {
+		if err := uc.groupRepository.AddMember(ctx, groupID, user.ID); err != nil {
+			slog.WarnContext(ctx, "failed to add invited user to group", "error", err, "group_id", groupID, "user_id", user.ID)
+		}
+	}
+
+	uc.publishEvent(ctx, "invitation_accepted", user.ID, map[string]string{"invitation_id": id})
+
+	return user, nil
+}
+
+// ExpireInvitations marks pending invitations past their expiry as expired.
+// It is run periodically by the scheduler.
+func (uc *UseCase) ExpireInvitations(ctx context.Context) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ExpireInvitations")
+	defer span.End()
+
+	ids, err := uc.invitationRepository.ExpireStale(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to expire invitations: %w", err)
+	}
+
+	for _, id := range ids {
+		uc.publishInvitationEvent(ctx, "invitation_expired", id, "")
+	}
+
+	if len(ids) > 0 {
+		slog.InfoContext(ctx, "invitations expired", "count", len(ids))
+	}
+
+	return nil
+}
+
+func (uc *UseCase) sendInvitation(ctx context.Context, invitation domain.Invitation, token string) {
+	if uc.notifier == nil {
+		slog.WarnContext(ctx, "no notifier configured, invitation not sent", "invitation_id", invitation.ID)
+		return
+	}
+
+	link, err := url.Parse(uc.cfg.Invitations.AcceptURL)
+	if err != nil {
+		slog.ErrorContext(ctx, "invalid invitation accept url", "error", err)
+		return
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	notification := domain.Notification{
+		To:      invitation.Email,
+		Subject: "You have been invited",
+		Body: fmt.Sprintf("You have been invited to create an account. The invitation expires at %s.",
+			invitation.ExpiresAt.UTC().Format(time.RFC1123)),
+		Link: link.String(),
+	}
+
+	if err := uc.notifier.Send(ctx, notification); err != nil {
+		slog.ErrorContext(ctx, "failed to send invitation", "error", err, "invitation_id", invitation.ID)
+	}
+}
+
+func (uc *UseCase) publishInvitationEvent(ctx context.Context, method, invitationID, actorID string) {
+	attributes := map[string]string{"invitation_id": invitationID}
+	if actorID != "" {
+		attributes["actor_id"] = actorID
+	}
+	uc.publishEvent(ctx, method, "", attributes)
+}
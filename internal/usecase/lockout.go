@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+func accountScope(userID string) string {
+	return "account:" + userID
+}
+
+func ipScope(ip string) string {
+	return "ip:" + ip
+}
+
+// checkLoginAllowed rejects the attempt when the account is locked or when
+// the account or source IP is still in a backoff period. Redis failures are
+// logged and do not block logins.
+func (uc *UseCase) checkLoginAllowed(ctx context.Context, user *domain.User, ip string) error {
+	now := time.Now()
+	if user != nil && user.IsLocked(now) {
+		return &domain.ThrottledError{Err: domain.ErrAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+	}
+
+	if uc.loginAttempts == nil {
+		return nil
+	}
+
+	scopes := make([]string, 0, 2)
+	if ip != "" {
+		scopes = append(scopes, ipScope(ip))
+	}
+	if user != nil {
+		scopes = append(scopes, accountScope(user.ID))
+	}
+
+	for _, scope := range scopes {
+		wait, err := uc.loginAttempts.Backoff(ctx, scope)
+		if err != nil {
+			slog.ErrorContext(ctx, "uc.loginAttempts.Backoff", "err", err, "scope", scope)
+			continue
+		}
+		if wait > 0 {
+			return &domain.ThrottledError{Err: domain.ErrTooManyAttempts, RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt for the source IP and, when
+// known, the account. Repeated failures trigger exponential backoff and
+// eventually a temporary account lock.
+func (uc *UseCase) recordLoginFailure(ctx context.Context, user *domain.User, ip string) {
+	if uc.loginAttempts == nil {
+		return
+	}
+
+	cfg := uc.cfg.Lockout
+
+	if ip != "" {
+		failures, err := uc.loginAttempts.RecordFailure(ctx, ipScope(ip), cfg.Window)
+		if err != nil {
+			slog.ErrorContext(ctx, "uc.loginAttempts.RecordFailure", "err", err, "ip", ip)
+		} else {
+			delay := backoffDelay(failures, cfg.BackoffFreeAttempts, cfg.BackoffBase, cfg.BackoffMax)
+			if failures >= cfg.IPThreshold {
+				delay = cfg.Duration
+			}
+			uc.setBackoff(ctx, ipScope(ip), delay)
+		}
+	}
+
+	if user == nil {
+		return
+	}
+
+	failures, err := uc.loginAttempts.RecordFailure(ctx, accountScope(user.ID), cfg.Window)
+	if err != nil {
+		slog.ErrorContext(ctx, "uc.loginAttempts.RecordFailure", "err", err, "user_id", user.ID)
+		return
+	}
+
+	if failures < cfg.AccountThreshold {
+		uc.setBackoff(ctx, accountScope(user.ID), backoffDelay(failures, cfg.BackoffFreeAttempts, cfg.BackoffBase, cfg.BackoffMax))
+		return
+	}
+
+	lockedUntil := time.Now().Add(cfg.Duration)
+	if err := uc.repository.SetLockedUntil(ctx, user.ID, &lockedUntil); err != nil {
+		slog.ErrorContext(ctx, "failed to lock account", "err", err, "user_id", user.ID)
+		return
+	}
+
+	if err := uc.loginAttempts.Reset(ctx, accountScope(user.ID)); err != nil {
+		slog.ErrorContext(ctx, "uc.loginAttempts.Reset", "err", err, "user_id", user.ID)
+	}
+
+	slog.WarnContext(ctx, "account locked after failed logins", "user_id", user.ID, "failures", failures, "locked_until", lockedUntil)
+
+	uc.publishEvent(ctx, "account_locked", user.ID, map[string]string{
+		"ip":           ip,
+		"failures":     strconv.FormatInt(failures, 10),
+		"locked_until": lockedUntil.Format(time.RFC3339),
+	})
+}
+
+func (uc *UseCase) setBackoff(ctx context.Context, scope string, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+
+	if err := uc.loginAttempts.SetBackoff(ctx, scope, delay); err != nil {
+		slog.ErrorContext(ctx, "uc.loginAttempts.SetBackoff", "err", err, "scope", scope)
+	}
+}
+
+// resetLoginFailures forgets failed attempts and clears an expired lock after
+// a successful login.
+func (uc *UseCase) resetLoginFailures(ctx context.Context, user domain.User) {
+	if uc.loginAttempts != nil {
+		if err := uc.loginAttempts.Reset(ctx, accountScope(user.ID)); err != nil {
+			slog.ErrorContext(ctx, "uc.loginAttempts.Reset", "err", err, "user_id", user.ID)
+		}
+	}
+
+	if user.LockedUntil != nil {
+		if err := uc.repository.SetLockedUntil(ctx, user.ID, nil); err != nil {
+			slog.ErrorContext(ctx, "failed to clear account lock", "err", err, "user_id", user.ID)
+		}
+	}
+}
+
+// UnlockUser clears an account lock and its failure counters on behalf of an
+// admin.
+func (uc *UseCase) UnlockUser(ctx context.Context, id, actorID string) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.UnlockUser")
+	defer span.End()
+
+	if err := uc.repository.SetLockedUntil(ctx, id, nil); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		slog.ErrorContext(ctx, "failed to unlock user", "error", err, "user_id", id)
+		return domain.User{}, fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	if uc.loginAttempts != nil {
+		if err := uc.loginAttempts.Reset(ctx, accountScope(id)); err != nil {
+			slog.ErrorContext(ctx, "uc.loginAttempts.Reset", "err", err, "user_id", id)
+		}
+	}
+
+	slog.InfoContext(ctx, "user unlocked by admin", "user_id", id, "actor_id", actorID)
+
+	uc.publishEvent(ctx, "account_unlocked", id, map[string]string{"actor_id": actorID})
+
+	user, err := uc.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("failed to get unlocked user: %w", err)
+	}
+
+	return user, nil
+}
+
+// backoffDelay doubles the delay for every failure past the free attempts,
+// starting at base and capped at max.
+func backoffDelay(failures, free int64, base, max time.Duration) time.Duration {
+	if failures <= free {
+		return 0
+	}
+
+	delay := base
+	for i := free + 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, max)
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+func (uc *UseCase) Login(ctx context.Context, req domain.LoginRequest) (domain.LoginResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.Login")
+	defer span.End()
+
+	if req.Email == "" || req.Password == "" {
+		return domain.LoginResult{}, domain.ErrInvalidCredentials
+	}
+
+	if err := uc.checkLoginAllowed(ctx, nil, req.IP); err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	user, err := uc.repository.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// Unknown accounts fail like wrong passwords, in the same time.
+			auth.CheckPassword("", req.Password)
+			uc.recordLoginFailure(ctx, nil, req.IP)
+			return domain.LoginResult{}, domain.ErrInvalidCredentials
+		}
+		slog.ErrorContext(ctx, "failed to get user for login", "error", err)
+		return domain.LoginResult{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	passwordOK := auth.CheckPassword(user.PasswordHash, req.Password)
+
+	// Locked and throttled accounts fail like wrong passwords, so that the
+	// response tells neither which accounts exist nor when a guess during a
+	// lock was right.
+	if err := uc.checkLoginAllowed(ctx, &user, ""); err != nil {
+		slog.WarnContext(ctx, "login of locked or throttled account", "user_id", user.ID, "error", err)
+		return domain.LoginResult{}, domain.ErrInvalidCredentials
+	}
+
+	if !passwordOK {
+		slog.WarnContext(ctx, "invalid password", "user_id", user.ID)
+		uc.recordLoginFailure(ctx, &user, req.IP)
+		return domain.LoginResult{}, domain.ErrInvalidCredentials
+	}
+
+	if user.Status != domain.StatusActive {
+		slog.WarnContext(ctx, "login of inactive user", "user_id", user.ID, "status", user.Status)
+		return domain.LoginResult{}, domain.ErrAccountInactive
+	}
+
+	mfa, err := uc.mfaRepository.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		slog.ErrorContext(ctx, "failed to get mfa for login", "error", err, "user_id", user.ID)
+		return domain.LoginResult{}, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	switch {
+	case mfa.Enabled:
+		return uc.issueMFAChallenge(user, domain.TokenPurposeMFA)
+	case uc.mfaRequired(user.Role):
+		return uc.issueMFAChallenge(user, domain.TokenPurposeMFAEnroll)
+	}
+
+	uc.resetLoginFailures(ctx, user)
+
+	uc.RecordLogin(ctx, user.ID)
+
+	slog.InfoContext(ctx, "user logged in", "user_id", user.ID)
+	return uc.issueAccessToken(user)
+}
+
+// LoginMFA completes a login started by Login when the user has MFA enabled.
+// Either a TOTP code or an unused recovery code is accepted.
+func (uc *UseCase) LoginMFA(ctx context.Context, req domain.LoginMFARequest) (domain.LoginResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.LoginMFA")
+	defer span.End()
+
+	principal, err := uc.Authenticate(ctx, req.MFAToken, domain.TokenPurposeMFA)
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	user, err := uc.repository.GetByID(ctx, principal.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.LoginResult{}, domain.ErrUnauthorized
+		}
+		return domain.LoginResult{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := uc.checkLoginAllowed(ctx, &user, req.IP); err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	if user.Status != domain.StatusActive {
+		return domain.LoginResult{}, domain.ErrAccountInactive
+	}
+
+	if err := uc.verifySecondFactor(ctx, user.ID, req); err != nil {
+		// A user without MFA cannot hold an MFA token, treat it as a bad code.
+		if errors.Is(err, domain.ErrMFANotEnabled) {
+			err = domain.ErrInvalidMFACode
+		}
+		if errors.Is(err, domain.ErrInvalidMFACode) {
+			uc.recordLoginFailure(ctx, &user, req.IP)
+		}
+		return domain.LoginResult{}, err
+	}
+
+	uc.resetLoginFailures(ctx, user)
+
+	uc.RecordLogin(ctx, user.ID)
+
+	slog.InfoContext(ctx, "user logged in", "user_id", user.ID, "mfa", true)
+	return uc.issueAccessToken(user)
+}
+
+func (uc *UseCase) verifySecondFactor(ctx context.Context, userID string, req domain.LoginMFARequest) error {
+	if req.RecoveryCode != "" {
+		used, err := uc.mfaRepository.UseRecoveryCode(ctx, userID, auth.HashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			return fmt.Errorf("failed to use recovery code: %w", err)
+		}
+		if !used {
+			slog.WarnContext(ctx, "invalid recovery code", "user_id", userID)
+			return domain.ErrInvalidMFACode
+		}
+		slog.InfoContext(ctx, "recovery code used", "user_id", userID)
+		return nil
+	}
+
+	return uc.verifyTOTP(ctx, userID, req.Code)
+}
+
+// Authenticate validates a bearer token and checks that it was issued for one
+// of the allowed purposes. The user, and the admin of an impersonation
+// session, must still be active, and the role is taken from the user rather
+// than the token, so that suspensions and demotions apply immediately.
+func (uc *UseCase) Authenticate(ctx context.Context, token string, purposes ...string) (domain.Principal, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.Authenticate")
+	defer span.End()
+
+	principal, err := uc.tokens.Parse(token)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to parse token", "error", err)
+		return domain.Principal{}, domain.ErrUnauthorized
+	}
+
+	if !slices.Contains(purposes, principal.Purpose) {
+		return domain.Principal{}, domain.ErrUnauthorized
+	}
+
+	user, err := uc.activeUser(ctx, principal.UserID)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	principal.Role = user.Role
+
+	if principal.Impersonated() {
+		actor, err := uc.activeUser(ctx, principal.ActorID)
+		if err != nil {
+			return domain.Principal{}, err
+		}
+		if actor.Role != domain.RoleAdmin {
+			slog.WarnContext(ctx, "impersonation by a non-admin rejected", "actor_id", actor.ID, "session_id", principal.SessionID)
+			return domain.Principal{}, domain.ErrUnauthorized
+		}
+	}
+
+	return principal, nil
+}
+
+// activeUser returns the user behind a token. Unknown and inactive users are
+// unauthorized.
+func (uc *UseCase) activeUser(ctx context.Context, id string) (domain.User, error) {
+	user, err := uc.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrUnauthorized
+		}
+		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Status != domain.StatusActive {
+		slog.WarnContext(ctx, "token of inactive user rejected", "user_id", id, "status", user.Status)
+		return domain.User{}, fmt.Errorf("%w: account is not active", domain.ErrUnauthorized)
+	}
+
+	return user, nil
+}
+
+func (uc *UseCase) issueAccessToken(user domain.User) (domain.LoginResult, error) {
+	token, expiresAt, err := uc.tokens.Issue(user.ID, user.Role, domain.TokenPurposeAccess, uc.cfg.Auth.AccessTokenTTL)
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	return domain.LoginResult{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (uc *UseCase) issueMFAChallenge(user domain.User, purpose string) (domain.LoginResult, error) {
+	token, expiresAt, err := uc.tokens.Issue(user.ID, user.Role, purpose, uc.cfg.Auth.MFATokenTTL)
+	if err != nil {
+		return domain.LoginResult{}, err
+	}
+
+	return domain.LoginResult{
+		MFARequired:           purpose == domain.TokenPurposeMFA,
+		MFAEnrollmentRequired: purpose == domain.TokenPurposeMFAEnroll,
+		MFAToken:              token,
+		ExpiresAt:             expiresAt,
+	}, nil
+}
+
+func (uc *UseCase) mfaRequired(role string) bool {
+	return slices.Contains(uc.cfg.Auth.MFARequiredRoles, role)
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// EnrollMFA generates a new TOTP secret for the user. The secret stays
+// inactive until ConfirmMFA is called with a valid code.
+func (uc *UseCase) EnrollMFA(ctx context.Context, userID string) (domain.MFAEnrollment, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.EnrollMFA")
+	defer span.End()
+
+	user, err := uc.repository.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.MFAEnrollment{}, domain.ErrNotFound
+		}
+		return domain.MFAEnrollment{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return domain.MFAEnrollment{}, err
+	}
+
+	if err := uc.mfaRepository.SavePending(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			return domain.MFAEnrollment{}, domain.ErrMFAAlreadyEnabled
+		}
+		return domain.MFAEnrollment{}, fmt.Errorf("failed to save mfa: %w", err)
+	}
+
+	slog.InfoContext(ctx, "mfa enrollment started", "user_id", user.ID)
+
+	return domain.MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: auth.OTPAuthURI(uc.cfg.Auth.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA activates a pending enrollment with the first code from the
+// authenticator and returns freshly generated recovery codes. The codes are
+// only returned here; just their hashes are stored.
+func (uc *UseCase) ConfirmMFA(ctx context.Context, userID, code string) (domain.MFAConfirmation, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ConfirmMFA")
+	defer span.End()
+
+	mfa, err := uc.mfaRepository.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.MFAConfirmation{}, domain.ErrMFANotEnabled
+		}
+		return domain.MFAConfirmation{}, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	if mfa.Enabled {
+		return domain.MFAConfirmation{}, domain.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		return domain.MFAConfirmation{}, domain.ErrInvalidMFACode
+	}
+
+	codes, err := auth.GenerateRecoveryCodes()
+	if err != nil {
+		return domain.MFAConfirmation{}, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, auth.HashRecoveryCode(c))
+	}
+
+	if err := uc.mfaRepository.Enable(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, domain.ErrMFAAlreadyEnabled) {
+			return domain.MFAConfirmation{}, domain.ErrMFAAlreadyEnabled
+		}
+		return domain.MFAConfirmation{}, fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	uc.publishEvent(ctx, "mfa_enrolled", userID, nil)
+
+	return domain.MFAConfirmation{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA lets a user turn off their own MFA. Users whose role requires MFA
+// cannot disable it and have to ask an admin for a reset instead.
+func (uc *UseCase) DisableMFA(ctx context.Context, userID, code string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.DisableMFA")
+	defer span.End()
+
+	user, err := uc.repository.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if uc.mfaRequired(user.Role) {
+		return domain.ErrForbidden
+	}
+
+	if err := uc.verifyTOTP(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := uc.mfaRepository.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa: %w", err)
+	}
+
+	uc.publishEvent(ctx, "mfa_disabled", userID, map[string]string{"reason": "user"})
+
+	return nil
+}
+
+// ResetMFA removes the MFA configuration and recovery codes of a user on
+// behalf of an admin, e.g. after a lost device.
+func (uc *UseCase) ResetMFA(ctx context.Context, userID, actorID string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ResetMFA")
+	defer span.End()
+
+	if err := uc.mfaRepository.Delete(ctx, userID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrMFANotEnabled
+		}
+		slog.ErrorContext(ctx, "failed to reset mfa", "error", err, "user_id", userID)
+		return fmt.Errorf("failed to reset mfa: %w", err)
+	}
+
+	slog.InfoContext(ctx, "mfa reset by admin", "user_id", userID, "actor_id", actorID)
+
+	uc.publishEvent(ctx, "mfa_disabled", userID, map[string]string{
+		"reason":   "admin_reset",
+		"actor_id": actorID,
+	})
+
+	return nil
+}
+
+func (uc *UseCase) verifyTOTP(ctx context.Context, userID, code string) error {
+	mfa, err := uc.mfaRepository.Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrMFANotEnabled
+		}
+		return fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	if !mfa.Enabled {
+		return domain.ErrMFANotEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(mfa.Secret, code, time.Now())
+	if !ok {
+		slog.WarnContext(ctx, "invalid totp code", "user_id", userID)
+		return domain.ErrInvalidMFACode
+	}
+
+	advanced, err := uc.mfaRepository.AdvanceStep(ctx, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	if !advanced {
+		slog.WarnContext(ctx, "totp code replayed", "user_id", userID)
+		return domain.ErrInvalidMFACode
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+func (uc *UseCase) OIDCDiscovery() domain.OIDCDiscovery {
+	issuer := strings.TrimSuffix(uc.cfg.OIDC.IssuerURL, "/")
+
+	return domain.OIDCDiscovery{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth2/authorize",
+		TokenEndpoint:                     issuer + "/oauth2/token",
+		UserInfoEndpoint:                  issuer + "/userinfo",
+		JWKSURI:                           issuer + "/oauth2/jwks",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		ScopesSupported:                   []string{domain.OIDCScopeOpenID, domain.OIDCScopeProfile, domain.OIDCScopeEmail},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "email", "updated_at"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+	}
+}
+
+func (uc *UseCase) OIDCJWKS() domain.JWKS {
+	return uc.oidcKeys.JWKS()
+}
+
+func (uc *UseCase) RegisterOIDCClient(ctx context.Context, req domain.RegisterOIDCClientRequest) (domain.RegisteredOIDCClient, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.RegisterOIDCClient")
+	defer span.End()
+
+	if req.Name == "" || len(req.RedirectURIs) == 0 {
+		return domain.RegisteredOIDCClient{}, fmt.Errorf("%w: name and redirect_uris are required", domain.ErrInvalidInput)
+	}
+
+	for _, raw := range req.RedirectURIs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.Fragment != "" || u.User != nil {
+			return domain.RegisteredOIDCClient{}, fmt.Errorf("%w: invalid redirect uri %q", domain.ErrInvalidInput, raw)
+		}
+	}
+
+	client := domain.OIDCClient{
+		ID:           uuid.NewString(),
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Public:       req.Public,
+		CreatedAt:    time.Now(),
+	}
+
+	var secret string
+	if !client.Public {
+		s, err := auth.RandomToken(32)
+		if err != nil {
+			return domain.RegisteredOIDCClient{}, err
+		}
+		secret = s
+		client.SecretHash = auth.HashToken(secret)
+	}
+
+	if err := uc.oidcRepository.CreateClient(ctx, client); err != nil {
+		return domain.RegisteredOIDCClient{}, fmt.Errorf("failed to register client: %w", err)
+	}
+
+	return domain.RegisteredOIDCClient{OIDCClient: client, ClientSecret: secret}, nil
+}
+
+func (uc *UseCase) ListOIDCClients(ctx context.Context) ([]domain.OIDCClient, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ListOIDCClients")
+	defer span.End()
+
+	clients, err := uc.oidcRepository.ListClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list clients: %w", err)
+	}
+
+	return clients, nil
+}
+
+func (uc *UseCase) DeleteOIDCClient(ctx context.Context, id string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.DeleteOIDCClient")
+	defer span.End()
+
+	if err := uc.oidcRepository.DeleteClient(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+
+	return nil
+}
+
+// AuthorizeOIDC issues an authorization code for the authenticated user and
+// returns the URL to redirect the user agent to. Errors wrapping
+// domain.ErrInvalidInput mean the client or redirect URI cannot be trusted and
+// must not be redirected to; a *domain.OIDCError should be sent back to the
+// redirect URI. Impersonation sessions are denied, since the issued tokens
+// would not name the acting admin.
+func (uc *UseCase) AuthorizeOIDC(ctx context.Context, principal domain.Principal, req domain.OIDCAuthorizationRequest) (string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.AuthorizeOIDC")
+	defer span.End()
+
+	client, err := uc.oidcRepository.GetClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return "", fmt.Errorf("%w: unknown client", domain.ErrInvalidInput)
+		}
+		return "", fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return "", fmt.Errorf("%w: redirect uri is not registered", domain.ErrInvalidInput)
+	}
+
+	if req.ResponseType != "code" {
+		return "", &domain.OIDCError{Code: "unsupported_response_type"}
+	}
+
+	if !slices.Contains(strings.Fields(req.Scope), domain.OIDCScopeOpenID) {
+		return "", &domain.OIDCError{Code: "invalid_scope", Description: "openid scope is required"}
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return "", &domain.OIDCError{Code: "invalid_request", Description: "PKCE with S256 is required"}
+	}
+
+	if principal.Impersonated() {
+		slog.WarnContext(ctx, "oidc authorization during impersonation denied",
+			"session_id", principal.SessionID, "actor_id", principal.ActorID, "user_id", principal.UserID, "client_id", client.ID)
+		return "", &domain.OIDCError{Code: "access_denied", Description: "clients cannot be authorized while impersonating"}
+	}
+
+	code, err := auth.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err := uc.oidcRepository.SaveCode(ctx, domain.OIDCAuthorizationCode{
+		CodeHash:      auth.HashToken(code),
+		ClientID:      client.ID,
+		UserID:        principal.UserID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         req.Scope,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(uc.cfg.OIDC.CodeTTL),
+	}); err != nil {
+		return "", fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	slog.InfoContext(ctx, "oidc authorization code issued", "client_id", client.ID, "user_id", principal.UserID)
+
+	return redirectWithParams(req.RedirectURI, url.Values{"code": {code}, "state": {req.State}}), nil
+}
+
+func (uc *UseCase) ExchangeOIDCCode(ctx context.Context, req domain.OIDCTokenRequest) (domain.OIDCTokenResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ExchangeOIDCCode")
+	defer span.End()
+
+	if req.GrantType != "authorization_code" {
+		return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "unsupported_grant_type"}
+	}
+
+	client, err := uc.oidcRepository.GetClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_client"}
+		}
+		return domain.OIDCTokenResponse{}, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	if !client.Public {
+		hash := auth.HashToken(req.ClientSecret)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+			return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_client"}
+		}
+	}
+
+	// Reject requests that cannot match the code before consuming it, so a
+	// bad request does not burn a code the client could still redeem.
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_grant", Description: "redirect uri is not registered for the client"}
+	}
+
+	code, err := uc.oidcRepository.ConsumeCode(ctx, auth.HashToken(req.Code))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_grant", Description: "code is invalid, expired or already used"}
+		}
+		return domain.OIDCTokenResponse{}, fmt.Errorf("failed to consume code: %w", err)
+	}
+
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_grant", Description: "code was issued to another client or redirect uri"}
+	}
+
+	if !auth.VerifyPKCE(code.CodeChallenge, req.CodeVerifier) {
+		return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_grant", Description: "code verifier does not match"}
+	}
+
+	user, err := uc.repository.GetByID(ctx, code.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_grant", Description: "user no longer exists"}
+		}
+		return domain.OIDCTokenResponse{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Status != domain.StatusActive {
+		return domain.OIDCTokenResponse{}, &domain.OIDCError{Code: "invalid_grant", Description: "user is not active"}
+	}
+
+	now := time.Now()
+	issuer := strings.TrimSuffix(uc.cfg.OIDC.IssuerURL, "/")
+	scopes := strings.Fields(code.Scope)
+
+	idClaims := auth.OIDCClaims{
+		TokenUse: auth.TokenUseID,
+		Nonce:    code.Nonce,
+		AuthTime: jwt.NewNumericDate(code.AuthTime),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{client.ID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(uc.cfg.OIDC.IDTokenTTL)),
+		},
+	}
+	if slices.Contains(scopes, domain.OIDCScopeProfile) {
+		idClaims.Name = user.Name
+	}
+	if slices.Contains(scopes, domain.OIDCScopeEmail) {
+		idClaims.Email = user.Email
+	}
+
+	idToken, err := uc.oidcKeys.Sign(idClaims)
+	if err != nil {
+		return domain.OIDCTokenResponse{}, err
+	}
+
+	accessToken, err := uc.oidcKeys.Sign(auth.OIDCClaims{
+		TokenUse: auth.TokenUseAccess,
+		Scope:    code.Scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    issuer,
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{client.ID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(uc.cfg.Auth.AccessTokenTTL)),
+		},
+	})
+	if err != nil {
+		return domain.OIDCTokenResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "oidc tokens issued", "client_id", client.ID, "user_id", user.ID)
+
+	return domain.OIDCTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(uc.cfg.Auth.AccessTokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+// OIDCUserInfo maps the user behind an OIDC access token to standard claims,
+// limited to the scopes granted to the token.
+func (uc *UseCase) OIDCUserInfo(ctx context.Context, accessToken string) (domain.OIDCUserInfo, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.OIDCUserInfo")
+	defer span.End()
+
+	var claims auth.OIDCClaims
+	if err := uc.oidcKeys.Parse(accessToken, &claims, jwt.WithIssuer(strings.TrimSuffix(uc.cfg.OIDC.IssuerURL, "/"))); err != nil {
+		slog.WarnContext(ctx, "invalid userinfo token", "error", err)
+		return domain.OIDCUserInfo{}, domain.ErrUnauthorized
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if claims.TokenUse != auth.TokenUseAccess || !slices.Contains(scopes, domain.OIDCScopeOpenID) {
+		return domain.OIDCUserInfo{}, domain.ErrUnauthorized
+	}
+
+	user, err := uc.repository.GetByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.OIDCUserInfo{}, domain.ErrUnauthorized
+		}
+		return domain.OIDCUserInfo{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Status != domain.StatusActive {
+		return domain.OIDCUserInfo{}, domain.ErrUnauthorized
+	}
+
+	info := domain.OIDCUserInfo{Subject: user.ID}
+	if slices.Contains(scopes, domain.OIDCScopeProfile) {
+		info.Name = user.Name
+		info.UpdatedAt = user.UpdatedAt.Unix()
+	}
+	if slices.Contains(scopes, domain.OIDCScopeEmail) {
+		info.Email = user.Email
+	}
+
+	return info, nil
+}
+
+func redirectWithParams(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		for _, v := range values {
+			if v != "" {
+				query.Add(key, v)
+			}
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// OIDCErrorRedirect builds the redirect URI used to report an authorization
+// error back to the client.
+func OIDCErrorRedirect(redirectURI, state string, oidcErr *domain.OIDCError) string {
+	return redirectWithParams(redirectURI, url.Values{
+		"error":             {oidcErr.Code},
+		"error_description": {oidcErr.Description},
+		"state":             {state},
+	})
+}
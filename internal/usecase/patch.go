@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/jsonpatch"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// PatchUser applies a JSON Merge Patch or JSON Patch to the patchable fields of
+// a user. The patched document is validated as a whole and then persisted
+// through UpdateUser, so email changes are staged as usual.
+func (uc *UseCase) PatchUser(ctx context.Context, id, format string, patch []byte) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.PatchUser")
+	defer span.End()
+
+	user, err := uc.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	doc, err := json.Marshal(patchableUser(user))
+	if err != nil {
+		return domain.User{}, fmt.Errorf("failed to encode user: %w", err)
+	}
+
+	var patched []byte
+	switch format {
+	case domain.PatchFormatMerge:
+		patched, err = jsonpatch.Merge(doc, patch)
+	case domain.PatchFormatJSON:
+		patched, err = jsonpatch.Apply(doc, patch)
+	default:
+		return domain.User{}, fmt.Errorf("%w: unsupported patch format %q", domain.ErrInvalidInput, format)
+	}
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return domain.User{}, fmt.Errorf("%w: %v", domain.ErrPatchTestFailed, err)
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		return domain.User{}, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	case err != nil:
+		return domain.User{}, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	target, err := decodePatchedUser(patched)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	req := updateFromPatch(user, target)
+	if req == (domain.UpdateUserRequest{}) {
+		return user, nil
+	}
+
+	return uc.UpdateUser(ctx, id, req)
+}
+
+func patchableUser(user domain.User) domain.PatchableUser {
+	doc := domain.PatchableUser{
+		Email: user.Email,
+		Name:  user.Name,
+		Role:  &user.Role,
+	}
+	if user.Username != "" {
+		doc.Username = &user.Username
+	}
+	return doc
+}
+
+// decodePatchedUser decodes and validates a patched document. Unknown fields
+// are rejected so that typos in patch paths do not silently do nothing.
+func decodePatchedUser(data []byte) (domain.PatchableUser, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var user domain.PatchableUser
+	if err := decoder.Decode(&user); err != nil {
+		return domain.PatchableUser{}, fmt.Errorf("%w: invalid patched user: %v", domain.ErrInvalidInput, err)
+	}
+
+	if user.Role == nil || *user.Role == "" {
+		role := domain.RoleUser
+		user.Role = &role
+	}
+
+	req := domain.CreateUserRequest{Email: user.Email, Name: user.Name, Role: *user.Role}
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return domain.PatchableUser{}, err
+	}
+	user.Email, user.Name, user.Role = req.Email, req.Name, &req.Role
+
+	return user, nil
+}
+
+// updateFromPatch returns an update request with only the fields that the
+// patch changed.
+func updateFromPatch(user domain.User, target domain.PatchableUser) domain.UpdateUserRequest {
+	var req domain.UpdateUserRequest
+
+	if target.Email != user.Email {
+		req.Email = &target.Email
+	}
+	if target.Name != user.Name {
+		req.Name = &target.Name
+	}
+	if *target.Role != user.Role {
+		req.Role = *target.Role
+	}
+
+	username := ""
+	if target.Username != nil {
+		username = *target.Username
+	}
+	if username != user.Username {
+		req.Username = &username
+	}
+
+	return req
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// PersistedQueriesEnabled reports whether GraphQL queries can be persisted,
+// which requires Redis so that all instances share them.
+func (uc *UseCase) PersistedQueriesEnabled() bool {
+	return uc.persistedQueries != nil
+}
+
+// GetPersistedQuery returns the GraphQL query registered under its SHA-256
+// hash, or domain.ErrNotFound if the hash is unknown.
+func (uc *UseCase) GetPersistedQuery(ctx context.Context, hash string) (string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetPersistedQuery")
+	defer span.End()
+
+	if uc.persistedQueries == nil {
+		return "", domain.ErrNotFound
+	}
+
+	query, err := uc.persistedQueries.Get(ctx, hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get persisted query: %w", err)
+	}
+	if query == "" {
+		return "", domain.ErrNotFound
+	}
+
+	return query, nil
+}
+
+// PersistQuery registers a GraphQL query under its SHA-256 hash. The hash is
+// verified, so that a client cannot register a query under another query's
+// hash.
+func (uc *UseCase) PersistQuery(ctx context.Context, hash, query string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.PersistQuery")
+	defer span.End()
+
+	if uc.persistedQueries == nil {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return fmt.Errorf("%w: persisted query hash does not match the query", domain.ErrInvalidInput)
+	}
+
+	if err := uc.persistedQueries.Save(ctx, hash, query, uc.cfg.GraphQL.PersistedQueryTTL); err != nil {
+		return fmt.Errorf("failed to persist query: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+// redisRetryInterval is how long rate limiting stays in-process after Redis
+// failed, so that requests do not wait for an unreachable Redis each time.
+const redisRetryInterval = 5 * time.Second
+
+// AllowRequest checks a request against limits and counts it if all of them
+// allow it. Limits are shared by all instances through Redis. Without Redis,
+// or while it is unreachable, every instance enforces the limits on its own,
+// so that an outage neither blocks nor unthrottles all traffic.
+func (uc *UseCase) AllowRequest(ctx context.Context, limits []domain.RateLimit) domain.RateLimitDecision {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.AllowRequest")
+	defer span.End()
+
+	if len(limits) == 0 {
+		return domain.RateLimitDecision{Allowed: true}
+	}
+
+	now := time.Now()
+	if uc.rateLimits != nil && uc.localRateLimits.tryRedis(now) {
+		states, err := uc.rateLimits.Allow(ctx, limits)
+		if err == nil {
+			if uc.localRateLimits.redisResult(now, nil) {
+				slog.InfoContext(ctx, "rate limits are shared through redis again")
+			}
+			return domain.NewRateLimitDecision(limits, states)
+		}
+		if uc.localRateLimits.redisResult(now, err) {
+			slog.WarnContext(ctx, "falling back to in-process rate limiting", "error", err)
+		}
+	}
+
+	return domain.NewRateLimitDecision(limits, uc.localRateLimits.allow(limits, now))
+}
+
+// localRateLimiter is the in-process counterpart of the GCRA script of
+// redis.RateLimitStorage.
+type localRateLimiter struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+
+	degraded     bool
+	retryRedisAt time.Time
+}
+
+func newLocalRateLimiter() *localRateLimiter {
+	return &localRateLimiter{tats: make(map[string]time.Time)}
+}
+
+func (l *localRateLimiter) allow(limits []domain.RateLimit, now time.Time) []domain.RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	states := make([]domain.RateLimitState, len(limits))
+	next := make([]time.Time, len(limits))
+	allowed := true
+	for i, limit := range limits {
+		interval := max(limit.Period/time.Duration(limit.Requests), time.Millisecond)
+		tat := l.tats[limit.Key]
+		if tat.Before(now) {
+			tat = now
+		}
+		next[i] = tat.Add(interval)
+
+		if wait := next[i].Sub(now) - limit.Period; wait > 0 {
+			allowed = false
+			states[i] = domain.RateLimitState{Reset: tat.Sub(now), RetryAfter: wait}
+			continue
+		}
+		states[i] = domain.RateLimitState{
+			Remaining: int((limit.Period - next[i].Sub(now)) / interval),
+			Reset:     next[i].Sub(now),
+		}
+	}
+
+	if allowed {
+		for i, limit := range limits {
+			l.tats[limit.Key] = next[i]
+		}
+	}
+
+	return states
+}
+
+// sweep forgets limits that are fully reset, at most once a minute.
+func (l *localRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, tat := range l.tats {
+		if tat.Before(now) {
+			delete(l.tats, key)
+		}
+	}
+}
+
+// tryRedis reports whether Redis should be used, which is not the case for
+// redisRetryInterval after it failed.
+func (l *localRateLimiter) tryRedis(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return !now.Before(l.retryRedisAt)
+}
+
+// redisResult records whether Redis failed and reports whether that changed,
+// so that the switch is logged once instead of on every request.
+func (l *localRateLimiter) redisResult(now time.Time, err error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	degraded := err != nil
+	if degraded {
+		l.retryRedisAt = now.Add(redisRetryInterval)
+	}
+
+	changed := l.degraded != degraded
+	l.degraded = degraded
+	return changed
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+)
+
+func TestLocalRateLimiter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	perSecond := domain.RateLimit{Key: "ip", Requests: 4, Period: time.Second}
+	perMinute := domain.RateLimit{Key: "key", Requests: 2, Period: time.Minute}
+
+	type step struct {
+		at     time.Duration
+		limits []domain.RateLimit
+		want   []domain.RateLimitState
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst up to the limit",
+			steps: []step{
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 3, Reset: 250 * time.Millisecond}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 2, Reset: 500 * time.Millisecond}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 1, Reset: 750 * time.Millisecond}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 0, Reset: time.Second}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Reset: time.Second, RetryAfter: 250 * time.Millisecond}}},
+			},
+		},
+		{
+			name: "refill one request per interval",
+			steps: []step{
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 3, Reset: 250 * time.Millisecond}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 2, Reset: 500 * time.Millisecond}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 1, Reset: 750 * time.Millisecond}}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 0, Reset: time.Second}}},
+				{100 * time.Millisecond, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Reset: 900 * time.Millisecond, RetryAfter: 150 * time.Millisecond}}},
+				{250 * time.Millisecond, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 0, Reset: time.Second}}},
+				{1250 * time.Millisecond, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 3, Reset: 250 * time.Millisecond}}},
+			},
+		},
+		{
+			name: "rejected request does not count against other limits",
+			steps: []step{
+				{0, []domain.RateLimit{perMinute}, []domain.RateLimitState{{Remaining: 1, Reset: 30 * time.Second}}},
+				{0, []domain.RateLimit{perMinute}, []domain.RateLimitState{{Remaining: 0, Reset: time.Minute}}},
+				{0, []domain.RateLimit{perSecond, perMinute}, []domain.RateLimitState{
+					{Remaining: 3, Reset: 250 * time.Millisecond},
+					{Reset: time.Minute, RetryAfter: 30 * time.Second},
+				}},
+				{0, []domain.RateLimit{perSecond}, []domain.RateLimitState{{Remaining: 3, Reset: 250 * time.Millisecond}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLocalRateLimiter()
+			for i, s := range tt.steps {
+				got := l.allow(s.limits, start.Add(s.at))
+				for j := range s.want {
+					if got[j] != s.want[j] {
+						t.Errorf("step %d, limit %s: got %+v, want %+v", i, s.limits[j].Key, got[j], s.want[j])
+					}
+				}
+			}
+		})
+	}
+}
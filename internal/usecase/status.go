@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+type statusTransition struct {
+	from  []string
+	to    string
+	event string
+}
+
+// statusTransitions is the lifecycle state machine. Every action lists the
+// statuses it may be applied to and the status it leads to; anything else is
+// rejected with domain.ErrInvalidTransition.
+var statusTransitions = map[string]statusTransition{
+	"activate": {
+		from:  []string{domain.StatusPending, domain.StatusSuspended, domain.StatusDisabled},
+		to:    domain.StatusActive,
+		event: "user_activated",
+	},
+	"suspend": {
+		from:  []string{domain.StatusActive},
+		to:    domain.StatusSuspended,
+		event: "user_suspended",
+	},
+	"disable": {
+		from:  []string{domain.StatusPending, domain.StatusActive, domain.StatusSuspended},
+		to:    domain.StatusDisabled,
+		event: "user_disabled",
+	},
+	"delete": {
+		from:  []string{domain.StatusPending, domain.StatusActive, domain.StatusSuspended, domain.StatusDisabled},
+		to:    domain.StatusDeleted,
+		event: "user_deleted",
+	},
+	// Restored users come back disabled and need an explicit activation.
+	"restore": {
+		from:  []string{domain.StatusDeleted},
+		to:    domain.StatusDisabled,
+		event: "user_restored",
+	},
+}
+
+// IsStatusAction reports whether action is a known lifecycle action.
+func IsStatusAction(action string) bool {
+	_, ok := statusTransitions[action]
+	return ok
+}
+
+// ChangeUserStatus applies a lifecycle action such as "suspend" to a user. A
+// reason is required and stored with the new status.
+func (uc *UseCase) ChangeUserStatus(ctx context.Context, id, action, reason string) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ChangeUserStatus")
+	defer span.End()
+
+	transition, ok := statusTransitions[action]
+	if !ok {
+		return domain.User{}, fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, action)
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return domain.User{}, fmt.Errorf("%w: reason is required", domain.ErrInvalidInput)
+	}
+
+	user, err := uc.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if !slices.Contains(transition.from, user.Status) {
+		slog.WarnContext(ctx, "invalid status transition", "user_id", id, "status", user.Status, "action", action)
+		return domain.User{}, fmt.Errorf("%w: cannot %s a %s user", domain.ErrInvalidTransition, action, user.Status)
+	}
+
+	if err := uc.repository.UpdateStatus(ctx, id, user.Status, transition.to, reason); err != nil {
+		if errors.Is(err, domain.ErrInvalidTransition) {
+			return domain.User{}, err
+		}
+		return domain.User{}, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	uc.publishEvent(ctx, transition.event, id, map[string]string{
+		"from":   user.Status,
+		"to":     transition.to,
+		"reason": reason,
+	})
+
+	updated, err := uc.repository.GetByID(ctx, id)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("failed to get updated user: %w", err)
+	}
+
+	return updated, nil
+}
+
+// SetUserActive maps a boolean active flag, as used by provisioning protocols,
+// onto the lifecycle: deactivating an active user suspends it and activating
+// any other user runs the activate transition.
+func (uc *UseCase) SetUserActive(ctx context.Context, id string, active bool, reason string) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.SetUserActive")
+	defer span.End()
+
+	user, err := uc.repository.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	switch {
+	case active && user.Status != domain.StatusActive:
+		return uc.ChangeUserStatus(ctx, id, "activate", reason)
+	case !active && user.Status == domain.StatusActive:
+		return uc.ChangeUserStatus(ctx, id, "suspend", reason)
+	}
+
+	return user, nil
+}
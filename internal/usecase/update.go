@@ -5,22 +5,61 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/highway-to-Golang/user-service/internal/auth"
 	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
 )
 
+// UpdateUser applies the fields set in req. Users may only update themselves
+// and only admins may change roles.
 func (uc *UseCase) UpdateUser(ctx context.Context, id string, req domain.UpdateUserRequest) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.UpdateUser")
+	defer span.End()
+
+	if err := authorizeSelfOrAdmin(ctx, id); err != nil {
+		return domain.User{}, err
+	}
+
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return domain.User{}, err
+	}
+
 	existingUser, err := uc.repository.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			return domain.User{}, domain.ErrNotFound
 		}
-		slog.Error("failed to get user for update", "error", err, "user_id", id)
+		slog.ErrorContext(ctx, "failed to get user for update", "error", err, "user_id", id)
 		return domain.User{}, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if req.Email != nil {
-		existingUser.Email = *req.Email
+	if req.Role != "" && req.Role != existingUser.Role {
+		if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.CanChangeRole() {
+			return domain.User{}, fmt.Errorf("%w: only admins can change roles", domain.ErrForbidden)
+		}
+	}
+
+	previousEmail := existingUser.Email
+	emailChangeRequested := false
+	if req.Email != nil && *req.Email != existingUser.Email {
+		if req.EmailVerified {
+			now := time.Now()
+			existingUser.Email = *req.Email
+			existingUser.EmailVerifiedAt = &now
+			existingUser.PendingEmail = ""
+		} else {
+			if err := uc.ensureEmailAvailable(ctx, *req.Email); err != nil {
+				return domain.User{}, err
+			}
+			existingUser.PendingEmail = *req.Email
+			emailChangeRequested = true
+		}
+	} else if req.Email != nil {
+		// Setting the current address again cancels a pending change.
+		existingUser.PendingEmail = ""
 	}
 	if req.Name != nil {
 		existingUser.Name = *req.Name
@@ -29,25 +68,48 @@ func (uc *UseCase) UpdateUser(ctx context.Context, id string, req domain.UpdateU
 		existingUser.Role = req.Role
 	}
 
-	slog.Info("updating user", "id", id, "email", existingUser.Email, "name", existingUser.Name)
+	previousUsernameKey := existingUser.UsernameKey
+	if req.Username != nil {
+		if *req.Username == "" {
+			existingUser.Username, existingUser.UsernameKey = "", ""
+		} else {
+			existingUser.Username, existingUser.UsernameKey, err = uc.claimUsername(ctx, id, *req.Username)
+			if err != nil {
+				return domain.User{}, err
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "updating user", "id", id, "email", existingUser.Email, "name", existingUser.Name)
 
 	if err := uc.repository.Update(ctx, id, existingUser); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			return domain.User{}, domain.ErrNotFound
 		}
-		slog.Error("failed to update user", "error", err, "user_id", id)
+		if errors.Is(err, domain.ErrConflict) {
+			return domain.User{}, err
+		}
+		slog.ErrorContext(ctx, "failed to update user", "error", err, "user_id", id)
 		return domain.User{}, fmt.Errorf("failed to update user: %w", err)
 	}
 
 	updatedUser, err := uc.repository.GetByID(ctx, id)
 	if err != nil {
-		slog.Error("failed to get updated user", "error", err, "user_id", id)
+		slog.ErrorContext(ctx, "failed to get updated user", "error", err, "user_id", id)
 		return domain.User{}, fmt.Errorf("failed to get updated user: %w", err)
 	}
 
+	if previousUsernameKey != "" && previousUsernameKey != updatedUser.UsernameKey {
+		uc.releaseUsername(ctx, id, previousUsernameKey)
+	}
+
+	if emailChangeRequested {
+		uc.requestEmailChange(ctx, updatedUser, previousEmail)
+	}
+
 	if uc.cfg.NATS.Enabled && uc.eventSink != nil {
 		if err := uc.eventSink.Publish(ctx, "update"); err != nil {
-			slog.Warn("failed to publish event", "error", err, "method", "update")
+			slog.WarnContext(ctx, "failed to publish event", "error", err, "method", "update")
 		}
 	}
 
@@ -2,39 +2,258 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/highway-to-Golang/user-service/config"
+	"github.com/highway-to-Golang/user-service/internal/auth"
+	"github.com/highway-to-Golang/user-service/internal/blob"
 	"github.com/highway-to-Golang/user-service/internal/domain"
 	"github.com/highway-to-Golang/user-service/internal/nats"
 	"github.com/highway-to-Golang/user-service/internal/redis"
+	"github.com/highway-to-Golang/user-service/internal/username"
 )
 
 type Repository interface {
 	Create(ctx context.Context, user domain.User) error
 	GetByID(ctx context.Context, id string) (domain.User, error)
-	GetAll(ctx context.Context) ([]domain.User, error)
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	GetByUsernameKey(ctx context.Context, key string) (domain.User, error)
+	HoldUsername(ctx context.Context, hold domain.UsernameHold) error
+	GetUsernameHold(ctx context.Context, key string) (domain.UsernameHold, error)
+	GetAll(ctx context.Context, filter domain.UserFilter) ([]domain.User, error)
 	Update(ctx context.Context, id string, user domain.User) error
+	MarkEmailVerified(ctx context.Context, id, email string) error
+	ConfirmEmailChange(ctx context.Context, id, email string) error
+	SetAvatar(ctx context.Context, id, version string, urls map[string]string) error
+	ListEmails(ctx context.Context, userID string) ([]domain.UserEmail, error)
+	AddEmail(ctx context.Context, userID, email string) error
+	RemoveEmail(ctx context.Context, userID, email string) error
+	PromoteEmail(ctx context.Context, userID, email string) error
+	SetLockedUntil(ctx context.Context, id string, lockedUntil *time.Time) error
+	UpdateStatus(ctx context.Context, id, from, to, reason string) error
+	RecordActivity(ctx context.Context, activity []domain.Activity) error
+	ListInactive(ctx context.Context, before time.Time) ([]domain.InactiveUser, error)
+	MarkInactivityWarned(ctx context.Context, id string, at time.Time) error
+}
+
+type MFARepository interface {
+	Get(ctx context.Context, userID string) (domain.MFA, error)
+	SavePending(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string, step int64, codeHashes []string) error
+	AdvanceStep(ctx context.Context, userID string, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID, codeHash string) (bool, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+type OIDCRepository interface {
+	CreateClient(ctx context.Context, client domain.OIDCClient) error
+	GetClient(ctx context.Context, id string) (domain.OIDCClient, error)
+	ListClients(ctx context.Context) ([]domain.OIDCClient, error)
+	DeleteClient(ctx context.Context, id string) error
+	SaveCode(ctx context.Context, code domain.OIDCAuthorizationCode) error
+	ConsumeCode(ctx context.Context, codeHash string) (domain.OIDCAuthorizationCode, error)
+}
+
+type GroupRepository interface {
+	Create(ctx context.Context, group domain.Group) error
+	GetByID(ctx context.Context, id string) (domain.Group, error)
+	GetAll(ctx context.Context) ([]domain.Group, error)
+	GetByUserIDs(ctx context.Context, userIDs []string) (map[string][]domain.Group, error)
+	GetMembers(ctx context.Context, groupIDs []string) (map[string][]domain.GroupMember, error)
+	Update(ctx context.Context, group domain.Group) error
+	AddMember(ctx context.Context, groupID, userID string) error
 	Delete(ctx context.Context, id string) error
 }
 
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation domain.Invitation) error
+	GetByID(ctx context.Context, id string) (domain.Invitation, error)
+	GetAll(ctx context.Context, status string) ([]domain.Invitation, error)
+	Renew(ctx context.Context, id, tokenHash string, expiresAt time.Time) error
+	UpdateStatus(ctx context.Context, id, from, to string) error
+	Claim(ctx context.Context, id, tokenHash string) error
+	Release(ctx context.Context, id string) error
+	SetUserID(ctx context.Context, id, userID string) error
+	ExpireStale(ctx context.Context, now time.Time) ([]string, error)
+}
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, verification domain.EmailVerification) error
+	Consume(ctx context.Context, tokenHash string) (domain.EmailVerification, error)
+}
+
+type ImpersonationRepository interface {
+	Create(ctx context.Context, session domain.ImpersonationSession) error
+	List(ctx context.Context, userID string) ([]domain.ImpersonationSession, error)
+	ListByUserIDs(ctx context.Context, userIDs []string) (map[string][]domain.ImpersonationSession, error)
+}
+
+// Notifier delivers notifications to users, e.g. by email.
+type Notifier interface {
+	Send(ctx context.Context, notification domain.Notification) error
+}
+
 type UseCase struct {
-	repository         Repository
-	eventSink          *nats.EventSink
-	idempotencyStorage *redis.IdempotencyStorage
-	cfg                *config.Config
+	repository                  Repository
+	mfaRepository               MFARepository
+	oidcRepository              OIDCRepository
+	groupRepository             GroupRepository
+	invitationRepository        InvitationRepository
+	emailVerificationRepository EmailVerificationRepository
+	impersonationRepository     ImpersonationRepository
+	notifier                    Notifier
+	usernames                   *username.Policy
+	blobs                       blob.Store
+	eventSink                   *nats.EventSink
+	idempotencyStorage          *redis.IdempotencyStorage
+	loginAttempts               *redis.LoginAttemptStorage
+	activity                    *redis.ActivityStorage
+	persistedQueries            *redis.PersistedQueryStorage
+	rateLimits                  *redis.RateLimitStorage
+	localRateLimits             *localRateLimiter
+	healthChecks                []HealthCheck
+	shuttingDown                atomic.Bool
+	tokens                      *auth.TokenIssuer
+	oidcKeys                    *auth.KeySet
+	cfg                         *config.Config
 
 	locksTTL       time.Duration
 	idempotencyTTL time.Duration
 }
 
-func New(repository Repository, eventSink *nats.EventSink, idempotencyStorage *redis.IdempotencyStorage, cfg *config.Config) *UseCase {
-	return &UseCase{
+type Option func(*UseCase)
+
+func WithMFARepository(repository MFARepository) Option {
+	return func(uc *UseCase) {
+		uc.mfaRepository = repository
+	}
+}
+
+func WithLoginAttemptStorage(storage *redis.LoginAttemptStorage) Option {
+	return func(uc *UseCase) {
+		uc.loginAttempts = storage
+	}
+}
+
+func WithActivityStorage(storage *redis.ActivityStorage) Option {
+	return func(uc *UseCase) {
+		uc.activity = storage
+	}
+}
+
+func WithPersistedQueryStorage(storage *redis.PersistedQueryStorage) Option {
+	return func(uc *UseCase) {
+		uc.persistedQueries = storage
+	}
+}
+
+func WithRateLimitStorage(storage *redis.RateLimitStorage) Option {
+	return func(uc *UseCase) {
+		uc.rateLimits = storage
+	}
+}
+
+func WithOIDC(repository OIDCRepository, keys *auth.KeySet) Option {
+	return func(uc *UseCase) {
+		uc.oidcRepository = repository
+		uc.oidcKeys = keys
+	}
+}
+
+func WithGroupRepository(repository GroupRepository) Option {
+	return func(uc *UseCase) {
+		uc.groupRepository = repository
+	}
+}
+
+func WithInvitationRepository(repository InvitationRepository) Option {
+	return func(uc *UseCase) {
+		uc.invitationRepository = repository
+	}
+}
+
+func WithEmailVerificationRepository(repository EmailVerificationRepository) Option {
+	return func(uc *UseCase) {
+		uc.emailVerificationRepository = repository
+	}
+}
+
+func WithUsernamePolicy(policy *username.Policy) Option {
+	return func(uc *UseCase) {
+		uc.usernames = policy
+	}
+}
+
+func WithBlobStore(store blob.Store) Option {
+	return func(uc *UseCase) {
+		uc.blobs = store
+	}
+}
+
+func WithImpersonationRepository(repository ImpersonationRepository) Option {
+	return func(uc *UseCase) {
+		uc.impersonationRepository = repository
+	}
+}
+
+func WithNotifier(notifier Notifier) Option {
+	return func(uc *UseCase) {
+		uc.notifier = notifier
+	}
+}
+
+// WithHealthChecks sets the dependency checks run by CheckReadiness.
+func WithHealthChecks(checks ...HealthCheck) Option {
+	return func(uc *UseCase) {
+		uc.healthChecks = append(uc.healthChecks, checks...)
+	}
+}
+
+func New(repository Repository, eventSink *nats.EventSink, idempotencyStorage *redis.IdempotencyStorage, cfg *config.Config, opts ...Option) *UseCase {
+	uc := &UseCase{
 		repository:         repository,
 		eventSink:          eventSink,
 		idempotencyStorage: idempotencyStorage,
+		localRateLimits:    newLocalRateLimiter(),
+		tokens:             auth.NewTokenIssuer(cfg.Auth.TokenSecret, cfg.Auth.Issuer),
 		cfg:                cfg,
 		locksTTL:           30 * time.Second,
 		idempotencyTTL:     24 * time.Hour,
 	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+// authorizeSelfOrAdmin returns domain.ErrForbidden unless the principal in
+// ctx may manage userID. Calls without a principal come from trusted callers
+// such as SCIM or background jobs and are allowed.
+func authorizeSelfOrAdmin(ctx context.Context, userID string) error {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !principal.CanManage(userID) {
+		return fmt.Errorf("%w: only the user or an admin can change this account", domain.ErrForbidden)
+	}
+	return nil
+}
+
+// publishEvent publishes an event about a user or other resource when NATS is
+// enabled. Publish failures are logged and never fail the calling operation.
+func (uc *UseCase) publishEvent(ctx context.Context, method, userID string, attributes map[string]string) {
+	if !uc.cfg.NATS.Enabled || uc.eventSink == nil {
+		return
+	}
+
+	event := nats.Event{
+		Method:     method,
+		UserID:     userID,
+		Attributes: attributes,
+	}
+	if err := uc.eventSink.PublishEvent(ctx, event); err != nil {
+		slog.WarnContext(ctx, "failed to publish event", "error", err, "method", method)
+	}
 }
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+)
+
+func (uc *UseCase) ListUserEmails(ctx context.Context, userID string) ([]domain.UserEmail, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.ListUserEmails")
+	defer span.End()
+
+	if _, err := uc.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	emails, err := uc.repository.ListEmails(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+
+	return emails, nil
+}
+
+// AddUserEmail adds a secondary address and sends a verification link to it.
+// Until it is verified the address is only a claim: it cannot be used to sign
+// in and does not keep anyone else from using it.
+func (uc *UseCase) AddUserEmail(ctx context.Context, userID, email string) (domain.UserEmail, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.AddUserEmail")
+	defer span.End()
+
+	if err := authorizeSelfOrAdmin(ctx, userID); err != nil {
+		return domain.UserEmail{}, err
+	}
+
+	req := domain.AddUserEmailRequest{Email: email}
+	req.Normalize()
+	if err := req.Validate(); err != nil {
+		return domain.UserEmail{}, err
+	}
+	email = req.Email
+
+	user, err := uc.GetUser(ctx, userID)
+	if err != nil {
+		return domain.UserEmail{}, err
+	}
+
+	if strings.EqualFold(email, user.PendingEmail) {
+		return domain.UserEmail{}, fmt.Errorf("%w: email change to this address is pending", domain.ErrConflict)
+	}
+
+	if err := uc.ensureEmailAvailable(ctx, email); err != nil {
+		return domain.UserEmail{}, err
+	}
+
+	if err := uc.repository.AddEmail(ctx, userID, email); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrConflict) {
+			return domain.UserEmail{}, err
+		}
+		return domain.UserEmail{}, fmt.Errorf("failed to add email: %w", err)
+	}
+
+	added, err := uc.findUserEmail(ctx, userID, email)
+	if err != nil {
+		return domain.UserEmail{}, err
+	}
+
+	uc.sendEmailVerification(ctx, user, email)
+	uc.publishEvent(ctx, "email_added", userID, nil)
+
+	return added, nil
+}
+
+func (uc *UseCase) RemoveUserEmail(ctx context.Context, userID, email string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.RemoveUserEmail")
+	defer span.End()
+
+	if err := authorizeSelfOrAdmin(ctx, userID); err != nil {
+		return err
+	}
+
+	current, err := uc.findUserEmail(ctx, userID, email)
+	if err != nil {
+		return err
+	}
+
+	if current.Primary {
+		return fmt.Errorf("%w: the primary email cannot be removed", domain.ErrInvalidInput)
+	}
+
+	if err := uc.repository.RemoveEmail(ctx, userID, current.Email); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return fmt.Errorf("failed to remove email: %w", err)
+	}
+
+	uc.publishEvent(ctx, "email_removed", userID, nil)
+
+	return nil
+}
+
+// PromoteUserEmail makes a verified secondary address the user's primary email.
+func (uc *UseCase) PromoteUserEmail(ctx context.Context, userID, email string) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.PromoteUserEmail")
+	defer span.End()
+
+	if err := authorizeSelfOrAdmin(ctx, userID); err != nil {
+		return domain.User{}, err
+	}
+
+	current, err := uc.findUserEmail(ctx, userID, email)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if current.Primary {
+		return uc.GetUser(ctx, userID)
+	}
+	if current.VerifiedAt == nil {
+		return domain.User{}, fmt.Errorf("%w: the email must be verified before it can become primary", domain.ErrInvalidInput)
+	}
+
+	if err := uc.repository.PromoteEmail(ctx, userID, current.Email); err != nil {
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrConflict) {
+			return domain.User{}, err
+		}
+		return domain.User{}, fmt.Errorf("failed to promote email: %w", err)
+	}
+
+	user, err := uc.GetUser(ctx, userID)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	uc.publishEvent(ctx, "email_promoted", userID, nil)
+
+	return user, nil
+}
+
+func (uc *UseCase) findUserEmail(ctx context.Context, userID, email string) (domain.UserEmail, error) {
+	emails, err := uc.ListUserEmails(ctx, userID)
+	if err != nil {
+		return domain.UserEmail{}, err
+	}
+
+	for _, e := range emails {
+		if strings.EqualFold(e.Email, email) {
+			return e, nil
+		}
+	}
+
+	return domain.UserEmail{}, domain.ErrNotFound
+}
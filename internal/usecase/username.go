@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/highway-to-Golang/user-service/internal/domain"
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+	"github.com/highway-to-Golang/user-service/internal/tracing"
+	"github.com/highway-to-Golang/user-service/internal/username"
+)
+
+// CheckUsernameAvailability reports whether a username could be claimed by a
+// new user right now, and why not otherwise.
+func (uc *UseCase) CheckUsernameAvailability(ctx context.Context, name string) (domain.UsernameAvailability, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.CheckUsernameAvailability")
+	defer span.End()
+
+	result := domain.UsernameAvailability{Username: name}
+
+	normalized, key, err := uc.normalizeUsername(name)
+	if err != nil {
+		result.Reason = domain.UsernameInvalid
+		if errors.Is(err, username.ErrReserved) {
+			result.Reason = domain.UsernameReserved
+		}
+		result.Message = err.Error()
+		return result, nil
+	}
+	result.Username = normalized
+
+	reason, err := uc.usernameUnavailable(ctx, "", key)
+	if err != nil {
+		return domain.UsernameAvailability{}, err
+	}
+	if reason != "" {
+		result.Reason = reason
+		return result, nil
+	}
+
+	result.Available = true
+	result.Reason = domain.UsernameAvailable
+	return result, nil
+}
+
+// GetUserByUsername looks a user up by the uniqueness key of a username.
+// Deleted users are not found.
+func (uc *UseCase) GetUserByUsername(ctx context.Context, name string) (domain.User, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UseCase.GetUserByUsername")
+	defer span.End()
+
+	_, key, err := uc.normalizeUsername(name)
+	if err != nil {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	user, err := uc.repository.GetByUsernameKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrNotFound
+		}
+		return domain.User{}, fmt.Errorf("failed to get user by username: %w", err)
+	}
+
+	if user.Status == domain.StatusDeleted {
+		return domain.User{}, domain.ErrNotFound
+	}
+
+	return user, nil
+}
+
+// claimUsername validates a username for the given user and returns its
+// normalized form and uniqueness key.
+func (uc *UseCase) claimUsername(ctx context.Context, userID, name string) (string, string, error) {
+	normalized, key, err := uc.normalizeUsername(name)
+	if err != nil {
+		return "", "", apperrors.Validation("invalid username", apperrors.Violation{Field: "username", Message: err.Error()})
+	}
+
+	reason, err := uc.usernameUnavailable(ctx, userID, key)
+	if err != nil {
+		return "", "", err
+	}
+	switch reason {
+	case domain.UsernameTaken:
+		return "", "", fmt.Errorf("%w: username is taken", domain.ErrConflict)
+	case domain.UsernameCooldown:
+		return "", "", fmt.Errorf("%w: username was released recently", domain.ErrConflict)
+	}
+
+	return normalized, key, nil
+}
+
+// usernameUnavailable returns why userID cannot use the username key, or an
+// empty string if it can. Users can always keep or reclaim their own username.
+func (uc *UseCase) usernameUnavailable(ctx context.Context, userID, key string) (string, error) {
+	owner, err := uc.repository.GetByUsernameKey(ctx, key)
+	switch {
+	case err == nil:
+		if owner.ID != userID {
+			return domain.UsernameTaken, nil
+		}
+		return "", nil
+	case !errors.Is(err, domain.ErrNotFound):
+		return "", fmt.Errorf("failed to check username: %w", err)
+	}
+
+	hold, err := uc.repository.GetUsernameHold(ctx, key)
+	switch {
+	case err == nil:
+		if hold.UserID != userID {
+			return domain.UsernameCooldown, nil
+		}
+	case !errors.Is(err, domain.ErrNotFound):
+		return "", fmt.Errorf("failed to check username hold: %w", err)
+	}
+
+	return "", nil
+}
+
+// releaseUsername keeps a username the user gave up from being claimed by
+// someone else during the configured cooldown.
+func (uc *UseCase) releaseUsername(ctx context.Context, userID, key string) {
+	if uc.cfg.Username.ReleaseCooldown <= 0 {
+		return
+	}
+
+	hold := domain.UsernameHold{
+		Key:         key,
+		UserID:      userID,
+		AvailableAt: time.Now().Add(uc.cfg.Username.ReleaseCooldown),
+	}
+	if err := uc.repository.HoldUsername(ctx, hold); err != nil {
+		slog.ErrorContext(ctx, "failed to hold released username", "error", err, "user_id", userID)
+	}
+}
+
+func (uc *UseCase) normalizeUsername(name string) (string, string, error) {
+	if uc.usernames == nil {
+		return "", "", fmt.Errorf("%w: usernames are not enabled", username.ErrInvalid)
+	}
+	return uc.usernames.Normalize(name)
+}
@@ -0,0 +1,30 @@
+package username
+
+import "strings"
+
+// confusables maps lowercase characters from other scripts to the Latin
+// letters they are commonly mistaken for. The list is partial: it covers the
+// look-alikes seen in impersonation attempts rather than the full Unicode
+// confusables table (UTS #39), so it reduces but does not rule out spoofing.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ї': 'i', 'ј': 'j',
+	'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'ѕ': 's', 'т': 't',
+	'у': 'y', 'х': 'x', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ӏ': 'l',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Latin look-alikes
+	'ɡ': 'g', 'ı': 'i', 'ȷ': 'j', 'ł': 'l', 'ſ': 's',
+}
+
+// skeleton replaces confusable characters so that look-alike usernames map to
+// the same key.
+func skeleton(name string) string {
+	return strings.Map(func(r rune) rune {
+		if replacement, ok := confusables[r]; ok {
+			return replacement
+		}
+		return r
+	}, name)
+}
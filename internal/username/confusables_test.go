@@ -0,0 +1,39 @@
+package username
+
+import "testing"
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"latin", "alice", "alice"},
+		{"cyrillic a", "аlice", "alice"},
+		{"cyrillic word", "раураl", "paypal"},
+		{"greek omicron", "bοb", "bob"},
+		{"latin script g", "ɡreg", "greg"},
+		{"dotless i", "ıvan", "ivan"},
+		{"digits kept", "user42", "user42"},
+		{"unmapped letters kept", "üß", "üß"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skeleton(tt.in); got != tt.want {
+				t.Errorf("skeleton(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfusablesMapToASCII(t *testing.T) {
+	for from, to := range confusables {
+		if to < 'a' || to > 'z' {
+			t.Errorf("%q maps to %q, want a lowercase ASCII letter", from, to)
+		}
+		if from < 0x80 {
+			t.Errorf("%q is ASCII and must not be mapped", from)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package username
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	ErrInvalid  = errors.New("invalid username")
+	ErrReserved = errors.New("username is reserved")
+)
+
+// Rules configure how usernames are normalized and validated.
+type Rules struct {
+	CaseFold          bool
+	Pattern           string
+	MinLength         int
+	MaxLength         int
+	DetectConfusables bool
+	Reserved          []string
+}
+
+// Policy normalizes usernames and decides whether they may be used.
+type Policy struct {
+	rules    Rules
+	pattern  *regexp.Regexp
+	reserved map[string]struct{}
+}
+
+func NewPolicy(rules Rules) (*Policy, error) {
+	pattern, err := regexp.Compile(rules.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid username pattern: %w", err)
+	}
+
+	p := &Policy{
+		rules:    rules,
+		pattern:  pattern,
+		reserved: make(map[string]struct{}, len(rules.Reserved)),
+	}
+	for _, word := range rules.Reserved {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+		p.reserved[p.key(p.fold(word))] = struct{}{}
+	}
+
+	return p, nil
+}
+
+// Normalize returns the username as it is stored and displayed, and the key
+// that decides uniqueness. Two usernames with the same key, e.g. differing
+// only in case or in look-alike characters from other scripts, are considered
+// the same.
+func (p *Policy) Normalize(name string) (string, string, error) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+	name = p.fold(norm.NFKC.String(name))
+
+	length := utf8.RuneCountInString(name)
+	if length < p.rules.MinLength || (p.rules.MaxLength > 0 && length > p.rules.MaxLength) {
+		return "", "", fmt.Errorf("%w: must be between %d and %d characters", ErrInvalid, p.rules.MinLength, p.rules.MaxLength)
+	}
+
+	if !p.pattern.MatchString(name) {
+		return "", "", fmt.Errorf("%w: contains characters that are not allowed", ErrInvalid)
+	}
+
+	key := p.key(name)
+	if _, ok := p.reserved[key]; ok {
+		return "", "", ErrReserved
+	}
+
+	return name, key, nil
+}
+
+func (p *Policy) fold(name string) string {
+	if !p.rules.CaseFold {
+		return name
+	}
+	return cases.Fold().String(name)
+}
+
+func (p *Policy) key(name string) string {
+	key := cases.Fold().String(name)
+	if p.rules.DetectConfusables {
+		key = skeleton(key)
+	}
+	return key
+}
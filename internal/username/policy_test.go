@@ -0,0 +1,87 @@
+package username
+
+import (
+	"errors"
+	"testing"
+)
+
+func testPolicy(t *testing.T, rules Rules) *Policy {
+	t.Helper()
+
+	p, err := NewPolicy(rules)
+	if err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	return p
+}
+
+var defaultRules = Rules{
+	CaseFold:          true,
+	Pattern:           `^[\p{L}\p{N}_.]+$`,
+	MinLength:         3,
+	MaxLength:         30,
+	DetectConfusables: true,
+	Reserved:          []string{"admin", " root ", ""},
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    Rules
+		in       string
+		wantName string
+		wantKey  string
+		wantErr  error
+	}{
+		{"plain", defaultRules, "alice", "alice", "alice", nil},
+		{"trimmed and at sign", defaultRules, "  @alice ", "alice", "alice", nil},
+		{"case folded", defaultRules, "Alice", "alice", "alice", nil},
+		{"fullwidth", defaultRules, "ａｌｉｃｅ", "alice", "alice", nil},
+		{"confusable key", defaultRules, "аlice", "аlice", "alice", nil},
+		{"too short", defaultRules, "al", "", "", ErrInvalid},
+		{"too long", defaultRules, "abcdefghijklmnopqrstuvwxyzabcde", "", "", ErrInvalid},
+		{"disallowed character", defaultRules, "ali-ce", "", "", ErrInvalid},
+		{"reserved", defaultRules, "Admin", "", "", ErrReserved},
+		{"reserved trimmed", defaultRules, "root", "", "", ErrReserved},
+		{"reserved look-alike", defaultRules, "аdmin", "", "", ErrReserved},
+		{
+			name:     "case kept without folding",
+			rules:    Rules{Pattern: `^\p{L}+$`, MinLength: 1, DetectConfusables: true},
+			in:       "Alice",
+			wantName: "Alice",
+			wantKey:  "alice",
+		},
+		{
+			name:     "confusables kept when disabled",
+			rules:    Rules{CaseFold: true, Pattern: `^\p{L}+$`, MinLength: 1},
+			in:       "аlice",
+			wantName: "аlice",
+			wantKey:  "аlice",
+		},
+		{
+			name:     "no maximum",
+			rules:    Rules{Pattern: `^a+$`, MinLength: 1},
+			in:       "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			wantName: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			wantKey:  "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, key, err := testPolicy(t, tt.rules).Normalize(tt.in)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if name != tt.wantName || key != tt.wantKey {
+				t.Errorf("Normalize(%q) = %q, %q, want %q, %q", tt.in, name, key, tt.wantName, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestNewPolicyRejectsInvalidPattern(t *testing.T) {
+	if _, err := NewPolicy(Rules{Pattern: "["}); err == nil {
+		t.Fatal("NewPolicy accepted an invalid pattern")
+	}
+}
@@ -0,0 +1,105 @@
+// Package validation checks request fields against declarative rules and
+// collects every violation of a request at once.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rule checks a value and returns a violation message, or "" if it is valid.
+type Rule func(value string) string
+
+type Validator struct {
+	violations []apperrors.Violation
+}
+
+// Field applies rules to the value of the named field in order. Only the
+// first violated rule of a field is reported.
+func (v *Validator) Field(name, value string, rules ...Rule) {
+	for _, rule := range rules {
+		if message := rule(value); message != "" {
+			v.violations = append(v.violations, apperrors.Violation{Field: name, Message: message})
+			return
+		}
+	}
+}
+
+// Err returns a validation error with all violations, or nil if there are
+// none.
+func (v *Validator) Err(message string) error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return apperrors.Validation(message, v.violations...)
+}
+
+// Normalize trims surrounding whitespace and converts s to Unicode NFC, so
+// that visually identical input is stored and compared identically.
+func Normalize(s string) string {
+	return norm.NFC.String(strings.TrimSpace(s))
+}
+
+func Required(value string) string {
+	if value == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// MaxLength limits the number of characters, not bytes.
+func MaxLength(n int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+// Email accepts a bare RFC 5322 address such as "jane@example.com" without a
+// display name or angle brackets.
+func Email(value string) string {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// MaxBytes limits the encoded length, e.g. for values hashed with bcrypt,
+// which only accepts 72 bytes.
+func MaxBytes(n int) Rule {
+	return func(value string) string {
+		if len(value) > n {
+			return fmt.Sprintf("must be at most %d bytes", n)
+		}
+		return ""
+	}
+}
+
+func OneOf(allowed ...string) Rule {
+	return func(value string) string {
+		if !slices.Contains(allowed, value) {
+			return fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+		}
+		return ""
+	}
+}
+
+// Printable rejects control and other non-printable characters.
+func Printable(value string) string {
+	for _, r := range value {
+		if !unicode.IsPrint(r) {
+			return "must not contain control characters"
+		}
+	}
+	return ""
+}
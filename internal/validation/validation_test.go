@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	apperrors "github.com/highway-to-Golang/user-service/internal/errors"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		value string
+		valid bool
+	}{
+		{"required set", Required, "x", true},
+		{"required empty", Required, "", false},
+		{"max length at limit", MaxLength(3), "abc", true},
+		{"max length over limit", MaxLength(3), "abcd", false},
+		{"max length counts characters", MaxLength(3), "äöü", true},
+		{"email bare address", Email, "jane@example.com", true},
+		{"email display name", Email, "Jane <jane@example.com>", false},
+		{"email angle brackets", Email, "<jane@example.com>", false},
+		{"email without domain", Email, "jane", false},
+		{"email empty", Email, "", false},
+		{"max bytes at limit", MaxBytes(6), "äöü", true},
+		{"max bytes counts bytes", MaxBytes(5), "äöü", false},
+		{"one of allowed", OneOf("user", "admin"), "admin", true},
+		{"one of other", OneOf("user", "admin"), "root", false},
+		{"one of is case sensitive", OneOf("user", "admin"), "Admin", false},
+		{"printable text", Printable, "Jane Doe ✓", true},
+		{"printable newline", Printable, "Jane\nDoe", false},
+		{"printable nul", Printable, "Jane\x00", false},
+		{"printable bidi override", Printable, "Jane\u202e", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := tt.rule(tt.value)
+			if valid := message == ""; valid != tt.valid {
+				t.Errorf("rule(%q) = %q, want valid %v", tt.value, message, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidatorReportsFirstViolationPerField(t *testing.T) {
+	var v Validator
+	v.Field("name", "", Required, MaxLength(1))
+	v.Field("email", "jane@example.com", Required, Email)
+	v.Field("role", strings.Repeat("x", 10), MaxLength(5), OneOf("user"))
+
+	err := v.Err("invalid user")
+
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("Err() = %v, want an *errors.Error", err)
+	}
+	if !errors.Is(err, apperrors.ErrInvalidInput) {
+		t.Errorf("Err() = %v, want ErrInvalidInput", err)
+	}
+
+	want := []apperrors.Violation{
+		{Field: "name", Message: "is required"},
+		{Field: "role", Message: "must be at most 5 characters"},
+	}
+	if !slices.Equal(appErr.Violations, want) {
+		t.Errorf("violations = %+v, want %+v", appErr.Violations, want)
+	}
+}
+
+func TestValidatorWithoutViolations(t *testing.T) {
+	var v Validator
+	v.Field("name", "Jane", Required)
+
+	if err := v.Err("invalid user"); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"  Jane  ", "Jane"},
+		{"Jose\u0301", "Jos\u00e9"},
+		{"\tJos\u00e9\n", "Jos\u00e9"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
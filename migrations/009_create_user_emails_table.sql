@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_emails (
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, email)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_emails_email ON user_emails(LOWER(email));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_emails_primary ON user_emails(user_id) WHERE is_primary;
+
+INSERT INTO user_emails (user_id, email, is_primary, verified_at, created_at)
+SELECT id, email, TRUE, email_verified_at, created_at FROM users
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS user_emails;
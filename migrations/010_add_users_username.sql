@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username VARCHAR(64);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username_key VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_key ON users(username_key);
+
+CREATE TABLE IF NOT EXISTS released_usernames (
+    username_key VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS released_usernames;
+DROP INDEX IF EXISTS idx_users_username_key;
+ALTER TABLE users DROP COLUMN IF EXISTS username_key;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
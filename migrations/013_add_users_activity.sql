@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS inactivity_warned_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_last_active ON users((COALESCE(last_seen_at, last_login_at, created_at)));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_last_active;
+ALTER TABLE users DROP COLUMN IF EXISTS inactivity_warned_at;
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Unverified secondary addresses are claims and must not keep the owner of an
+-- address from signing up or verifying it.
+DROP INDEX IF EXISTS idx_user_emails_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_emails_email ON user_emails(LOWER(email)) WHERE is_primary OR verified_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_user_emails_email_claims ON user_emails(LOWER(email)) WHERE NOT is_primary AND verified_at IS NULL;
+
+-- +goose Down
+DELETE FROM user_emails c
+WHERE NOT c.is_primary AND c.verified_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM user_emails o
+    WHERE LOWER(o.email) = LOWER(c.email) AND o.user_id <> c.user_id
+  );
+DROP INDEX IF EXISTS idx_user_emails_email_claims;
+DROP INDEX IF EXISTS idx_user_emails_email;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_emails_email ON user_emails(LOWER(email));